@@ -0,0 +1,58 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Command burlo-agent is a minimal remote agent: it hosts the agentrpc
+// Agent service and wraps whichever in-process hardware client(s) it's
+// configured for (today: a zwavejsws.Client), so that hardware can sit on
+// a Raspberry Pi near the Z-Wave stick while the main controller runs
+// elsewhere. It relays decoded zwave-js events up as agentrpc.Samples and
+// translates agentrpc.Commands from the controller into SetValue calls.
+package main
+
+import (
+	"burlo/v2/pkg/agentrpc"
+	"burlo/v2/pkg/appctx"
+	"burlo/v2/pkg/logger"
+	"burlo/v2/pkg/zwavejsws"
+	"os"
+)
+
+func main() {
+	log := logger.New("BurloAgent")
+
+	listenAddr := getenv("AGENT_LISTEN_ADDR", ":9090")
+	zwaveAddr := getenv("ZWAVE_ADDR", "ws://localhost:3000")
+	zwaveToken := os.Getenv("ZWAVE_TOKEN")
+
+	ctx, cancel := appctx.New()
+	defer cancel()
+
+	zwave := zwavejsws.NewClient(zwaveAddr, zwaveToken)
+	srv := newRelayServer(zwave, log)
+
+	go srv.runZWave(ctx)
+
+	log.Info("listening on %s, relaying %s", listenAddr, zwaveAddr)
+	if err := agentrpc.Serve(listenAddr, srv, ctx.Done()); err != nil {
+		log.Fatal("agent server stopped: %v", err)
+	}
+}
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
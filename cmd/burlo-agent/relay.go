@@ -0,0 +1,138 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"burlo/v2/pkg/agentrpc"
+	"burlo/v2/pkg/logger"
+	"burlo/v2/pkg/zwavejsws"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// relayServer implements agentrpc.AgentServer, relaying decoded zwave-js
+// events to a connected controller as Samples and dispatching Commands it
+// receives back down as zwave SetValue calls. It supports one connected
+// controller stream at a time, which is all a single agent process needs.
+type relayServer struct {
+	zwave   *zwavejsws.Client
+	log     *logger.Logger
+	samples chan agentrpc.Sample
+}
+
+func newRelayServer(zwave *zwavejsws.Client, log *logger.Logger) *relayServer {
+	r := &relayServer{zwave: zwave, log: log, samples: make(chan agentrpc.Sample, 32)}
+	zwave.OnEvent(r.handleEvent)
+	return r
+}
+
+// runZWave services the local zwave-js-server connection until ctx is
+// canceled, handing off reconnect/backoff/liveness entirely to
+// zwavejsws.Client.Run.
+func (r *relayServer) runZWave(ctx context.Context) {
+	r.zwave.Run(ctx)
+}
+
+// handleEvent decodes a zwave-js "value updated" event into a Sample and
+// queues it for delivery to the connected controller, if any.
+func (r *relayServer) handleEvent(event zwavejsws.Event) {
+	if event.Type != "value updated" {
+		return
+	}
+
+	var updated zwavejsws.UpdatedValue
+	if err := json.Unmarshal(event.Args, &updated); err != nil {
+		r.log.Error("failed to decode value updated event: %v", err)
+		return
+	}
+
+	value, ok := toFloat64(updated.NewValue)
+	if !ok {
+		return
+	}
+
+	sample := agentrpc.Sample{
+		NodeID:    fmt.Sprintf("%d", event.NodeID),
+		SensorID:  updated.PropertyName,
+		Value:     value,
+		Timestamp: time.Now(),
+	}
+
+	select {
+	case r.samples <- sample:
+	default:
+		r.log.Debug("sample buffer full, dropping %s/%s", sample.NodeID, sample.SensorID)
+	}
+}
+
+// Stream implements agentrpc.AgentServer: it forwards queued Samples to
+// the controller and relays Commands it sends back to the zwave device.
+func (r *relayServer) Stream(stream agentrpc.AgentService_StreamServer) error {
+	errCh := make(chan error, 1)
+
+	go func() {
+		for {
+			cmd, err := stream.Recv()
+			if err != nil {
+				errCh <- err
+				return
+			}
+			if err := r.dispatch(cmd); err != nil {
+				r.log.Error("failed to dispatch command: %v", err)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case sample := <-r.samples:
+			if err := stream.Send(&sample); err != nil {
+				return err
+			}
+		case err := <-errCh:
+			return err
+		}
+	}
+}
+
+// dispatch turns a Command from the controller into a zwave SetValue call.
+func (r *relayServer) dispatch(cmd *agentrpc.Command) error {
+	var nodeID int
+	if _, err := fmt.Sscanf(cmd.NodeID, "%d", &nodeID); err != nil {
+		return fmt.Errorf("invalid node id %q: %w", cmd.NodeID, err)
+	}
+	return r.zwave.SetValue(nodeID, cmd.CommandClass, cmd.Property, cmd.Value)
+}
+
+func toFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case bool:
+		if n {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
@@ -18,23 +18,45 @@ package main
 import (
 	"burlo/v2/internal/config"
 	"burlo/v2/internal/controller"
+	"burlo/v2/internal/datalogger"
 	"burlo/v2/internal/dx2w"
-	"burlo/v2/internal/emoncms"
+	"burlo/v2/internal/mqttbridge"
 	"burlo/v2/internal/phidgets"
 	"burlo/v2/internal/thermostat"
 	"burlo/v2/internal/weather"
 	"burlo/v2/pkg/appctx"
+	"burlo/v2/pkg/authz"
+	"burlo/v2/pkg/buildinfo"
 	"burlo/v2/pkg/eventbus"
 	"burlo/v2/pkg/logger"
+	"burlo/v2/pkg/metrics"
 	"burlo/v2/pkg/modbus"
 	"burlo/v2/pkg/rootserv"
-	"burlo/v2/pkg/service"
+	"burlo/v2/pkg/rpc"
 	"burlo/v2/pkg/sysmon"
+	"context"
+	"crypto/tls"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
+// serverRunFunc picks how the root server is exposed based on conf:
+// AutoCertDomain takes priority (a real cert renewed from Let's Encrypt),
+// then a static TLSCertFile/TLSKeyFile pair, falling back to plain HTTP
+// for local/behind-a-proxy deployments.
+func serverRunFunc(conf config.ServerConfig, server *rootserv.RootServer) func(ctx context.Context) {
+	switch {
+	case conf.AutoCertDomain != "":
+		return func(ctx context.Context) { server.RunAutoCert(ctx, conf.AutoCertDomain, conf.AutoCertCacheDir) }
+	case conf.TLSCertFile != "" && conf.TLSKeyFile != "":
+		return func(ctx context.Context) { server.RunTLS(ctx, conf.TLSCertFile, conf.TLSKeyFile) }
+	default:
+		return server.Run
+	}
+}
+
 func main() {
 
 	rootdir := os.Getenv("PROJECT_ROOT")
@@ -47,46 +69,116 @@ func main() {
 	appConf := config.LoadFile(filepath.Join(rootdir, "var/config/burlo.json"))
 	modbusConf := modbus.LoadConfig(filepath.Join(rootdir, "var/config/dx2w.modbus.yml"))
 
+	for component, level := range appConf.Logger.Levels {
+		logger.SetLevel(component, level)
+	}
+
 	fmt.Println(filepath.Join(rootdir, "var/logs/burlo.log"))
 	fmt.Println(filepath.Join(rootdir, "var/config/burlo.json"))
 	fmt.Println(filepath.Join(rootdir, "var/config/dx2w.modbus.yml"))
 
 	// use conf to pass eventbus to whoever needs it
 	appConf.EventBus = eventbus.New()
+	appConf.MetricsRegistry = sysmon.NewRegistry()
+	appConf.Authz = authz.NewIssuer(appConf.Auth.Secret)
 	appConf.DataDir = filepath.Join(rootdir, "var/cache")
 	appConf.RootDir = rootdir
 
-	ctx, ctxCancel := appctx.New()
+	// persist weather/thermostat updates to disk so consumers can replay
+	// from a saved offset after a crash instead of losing history
+	if err := appConf.EventBus.EnableDurability(appConf.DataDir); err != nil {
+		fmt.Println("failed to enable eventbus durability:", err)
+	}
+
+	shutdownGroup := appctx.NewGroup()
 
 	// init services
-	server := rootserv.New(":80")
-	sysMonitorService := sysmon.New()
+	server := rootserv.New(":80", appConf.MetricsRegistry)
+	sysMonitorService := sysmon.New(appConf.MetricsRegistry)
 	phidgetsService := phidgets.New(appConf)
-	controllerService := controller.New(appConf)
 	dx2wModbusService := dx2w.New(modbusConf, appConf)
+	controllerService := controller.New(appConf, phidgetsService, dx2wModbusService)
 	thermostatService := thermostat.NewZWaveThermostat(appConf)
 	weatherService := weather.NewLocalDX2W(dx2wModbusService, appConf)
-	dataLoggerService := emoncms.New(controllerService, dx2wModbusService, appConf)
-
-	// attach web handler enabled services
-	server.Attach("/logger", "Logger", logger.WebService())
-	server.Attach("/monitor", "System Monitor", sysMonitorService)
-	server.Attach("/phidgets", "Phidgets State", phidgetsService)
-	server.Attach("/dx2wModbus", "DX2W Modbus Registers", dx2wModbusService)
-	server.Attach("/thermostat", "Virtual Thermostat with ZWave", thermostatService)
-	server.Attach("/weather", "Weather Data", weatherService)
-
-	// start runnable services
-	exitCh := service.Start(ctx, ctxCancel, []service.Runnable{
-		phidgetsService,
-		dx2wModbusService,
-		controllerService,
-		thermostatService,
-		weatherService,
-		dataLoggerService,
-		server,
-	})
+	dataLoggerService := datalogger.New(controllerService, dx2wModbusService, appConf)
+	mqttBridgeService := mqttbridge.NewBridge(appConf, thermostat.ZWaveClient.SetValue)
+	metricsService := metrics.New(appConf.Metrics, appConf.MetricsRegistry)
+
+	var rpcTLSConfig *tls.Config
+	if appConf.RPC.TLSCertFile != "" && appConf.RPC.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(appConf.RPC.TLSCertFile, appConf.RPC.TLSKeyFile)
+		if err != nil {
+			fmt.Println("failed to load rpc tls cert:", err)
+		} else {
+			rpcTLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		}
+	}
+	var rpcIssuer *authz.Issuer
+	var rpcScopes rpc.MethodScopes
+	if appConf.RPC.RequireAuth {
+		rpcIssuer = appConf.Authz
+		rpcScopes = rpc.MethodScopes{
+			"/rpc.Thermostat/SetSetpoint":            "thermostat",
+			"/rpc.Thermostat/SetMode":                "thermostat",
+			"/rpc.DX2W/SetOutdoorAirDesignTempC":     "dx2w",
+			"/rpc.DX2W/SetHotWaterDesignTempC":       "dx2w",
+			"/rpc.DX2W/SetHotWaterMinTempC":          "dx2w",
+			"/rpc.DX2W/SetHotWaterDifferentialTempC": "dx2w",
+			"/rpc.DX2W/WriteRegister":                "dx2w",
+		}
+	}
+	rpcServer := rpc.NewServer(appConf.RPC.HTTPAddr, rpcTLSConfig, rpcIssuer, rpcScopes)
+	rpcServer.RegisterThermostat(thermostat.NewRPCServer(thermostatService))
+	rpcServer.RegisterDX2W(dx2w.NewRPCServer())
+
+	// Global middleware applies to every request regardless of subserver:
+	// request logging, panic recovery, and (if configured) CORS for a
+	// browser app served from a different origin.
+	server.Use(rootserv.LoggingMiddleware(logger.New("HTTPServer")))
+	server.Use(rootserv.RecoverMiddleware(logger.New("HTTPServer")))
+	if appConf.Server.CORSOrigin != "" {
+		server.Use(rootserv.CORSMiddleware(appConf.Server.CORSOrigin))
+	}
+
+	// attach web handler enabled services. /auth/token, /healthz, and
+	// /version stay open (the token endpoint gates itself on username/
+	// password in the POST body; health/build info are harmless to expose),
+	// everything else sits behind basic auth so the controller can be
+	// exposed beyond localhost without shipping its state in the clear.
+	tokenTTL := time.Duration(appConf.Auth.TokenTTLSeconds) * time.Second
+	server.Attach("/auth/token", "Auth Token", rootserv.TokenHandler(
+		appConf.Authz, appConf.Auth.Username, appConf.Auth.Password, "thermostat", tokenTTL))
+	server.AttachProtected("/logger", "Logger", "burlo", appConf.Auth.Username, appConf.Auth.Password, logger.WebService())
+	server.AttachProtected("/monitor", "System Monitor", "burlo", appConf.Auth.Username, appConf.Auth.Password, sysMonitorService)
+	server.AttachProtected("/phidgets", "Phidgets State", "burlo", appConf.Auth.Username, appConf.Auth.Password, phidgetsService)
+	server.AttachProtected("/dx2wModbus", "DX2W Modbus Registers", "burlo", appConf.Auth.Username, appConf.Auth.Password, dx2wModbusService)
+	server.AttachProtected("/thermostat", "Virtual Thermostat with ZWave", "burlo", appConf.Auth.Username, appConf.Auth.Password, thermostatService)
+	server.AttachProtected("/weather", "Weather Data", "burlo", appConf.Auth.Username, appConf.Auth.Password, weatherService)
+	server.Attach("/version", "Build Info", buildinfo.Handler())
+	server.Attach("/healthz", "Health", rootserv.HealthHandler(map[string]rootserv.HealthChecker{
+		"thermostat": thermostatService,
+		"dx2w":       dx2wModbusService,
+		"phidgets":   phidgetsService,
+		"disk":       sysmon.DiskHealthChecker{Path: rootdir, MinFreeBytes: 1 << 30},
+	}))
+
+	// Register runnable services. Priority controls Shutdown hook order
+	// (lower runs first): the controller forces actuators to a safe state
+	// before anything else, so the pump/LWT don't keep running at their
+	// last in-flight target while everything else is still unwinding.
+	shutdownGroup.Register(appctx.Registration{Name: "controller", Run: controllerService.Run, Shutdown: controllerService.Shutdown, Priority: 0})
+	shutdownGroup.Register(appctx.Registration{Name: "phidgets", Run: phidgetsService.Run, Priority: 10})
+	shutdownGroup.Register(appctx.Registration{Name: "dx2wModbus", Run: dx2wModbusService.Run, Priority: 10})
+	shutdownGroup.Register(appctx.Registration{Name: "thermostat", Run: thermostatService.Run, Priority: 10})
+	shutdownGroup.Register(appctx.Registration{Name: "weather", Run: weatherService.Run, Priority: 10})
+	shutdownGroup.Register(appctx.Registration{Name: "dataLogger", Run: dataLoggerService.Run, Priority: 20})
+	shutdownGroup.Register(appctx.Registration{Name: "mqttBridge", Run: mqttBridgeService.Run, Priority: 20})
+	shutdownGroup.Register(appctx.Registration{Name: "metrics", Run: metricsService.Run, Priority: 30})
+	shutdownGroup.Register(appctx.Registration{Name: "rootserv", Run: serverRunFunc(appConf.Server, server), Priority: 30})
+	if appConf.RPC.Enabled {
+		shutdownGroup.Register(appctx.Registration{Name: "rpc", Run: rpcServer.Run, Priority: 30})
+	}
 
 	// waits for all services to stop
-	os.Exit(<-exitCh)
+	os.Exit(shutdownGroup.Wait())
 }
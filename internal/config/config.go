@@ -16,7 +16,9 @@
 package config
 
 import (
+	"burlo/v2/pkg/authz"
 	"burlo/v2/pkg/eventbus"
+	"burlo/v2/pkg/sysmon"
 	"encoding/json"
 	"log"
 	"os"
@@ -37,10 +39,33 @@ type ThermostatConfig struct {
 	// Add Z-Wave conn info if applicable
 	ZWaveAddr     string `json:"zwave_addr"`
 	ZWaveDeviceId int    `json:"zwave_deviceId"`
+	// ZWaveToken, if set, is sent as an Authorization: Bearer header when
+	// dialing zwave-js-server, exercising its own auth if configured.
+	ZWaveToken string `json:"zwave_token"`
+
+	// ZWaveMode selects how the backend reaches zwave-js: "local" (the
+	// default) dials ZWaveAddr directly; "remote" dials a cmd/burlo-agent
+	// process at ZWaveAgentAddr instead, for running the Z-Wave stick off-box.
+	ZWaveMode      string `json:"zwave_mode"`
+	ZWaveAgentAddr string `json:"zwave_agent_addr"`
+
+	// BatteryLowPercent is the threshold (0-100) below which the backend
+	// reports battery_low = true.
+	BatteryLowPercent float64 `json:"battery_low_percent"`
+
+	// EcoSetpoint is the Energy Save Heating setpoint (CC 67 propertyKey 11).
+	EcoSetpoint float64 `json:"eco_setpoint_c"`
+
+	// BoostSetpoint is the setpoint driven while in Boost mode.
+	BoostSetpoint float64 `json:"boost_setpoint_c"`
+	// BoostMaxDurationSeconds caps how long a single boost request may run.
+	BoostMaxDurationSeconds int `json:"boost_max_duration_seconds"`
 }
 
 type PhidgetsConfig struct {
-	HTTPAddr          string `json:"http_addr"`
+	// ServerAddr is the host:port of the Phidget22 network server (the
+	// "phidget22network" daemon, or a VINT hub in network-server mode).
+	ServerAddr        string `json:"server_addr"`
 	CirculatorChannel int    `json:"circulator_channel"`
 	CirculatorHubPort int    `json:"circulator_hubport"`
 }
@@ -51,12 +76,114 @@ type DX2WConfig struct {
 }
 
 type ControllerConfig struct {
+	// ShutdownSafeLWT is the leaving-water-temperature target forced on
+	// shutdown, the same value normally used when system mode is OFF.
+	ShutdownSafeLWT float64 `json:"shutdown_safe_lwt_c"`
+}
+
+// LoggerConfig configures per-component log level overrides, e.g.
+// {"levels": {"zwave-js": "debug", "dx2w": "info", "*": "warn"}} applied at
+// startup via logger.SetLevel (level names are matched case-insensitively);
+// "*" sets the default for any component without its own entry, which
+// otherwise keeps the package-wide debug/info behavior.
+type LoggerConfig struct {
+	Levels map[string]string `json:"levels"`
+}
+
+// AuthConfig configures the JWT issuer shared by the thermostat websocket,
+// the zwave-js-server dialer, and the /auth/token minting endpoint.
+// Username/Password gate /auth/token; Secret signs every token it mints.
+type AuthConfig struct {
+	Secret          string `json:"secret"`
+	Username        string `json:"username"`
+	Password        string `json:"password"`
+	TokenTTLSeconds int    `json:"token_ttl_seconds"`
+}
+
+// SinkConfig configures one datalogger.Sink. Type selects which fields
+// below apply, following the same flat, string-selected shape as
+// modbus.ModbusConfig's Transport field.
+type SinkConfig struct {
+	Type string `json:"type"` // "emoncms", "mqtt", or "influx"
+
+	// emoncms
+	EmonCMSAddr   string `json:"emoncms_addr,omitempty"`
+	EmonCMSApiKey string `json:"emoncms_apikey,omitempty"`
+
+	// mqtt
+	MQTTBrokerURL   string `json:"mqtt_broker_url,omitempty"`
+	MQTTClientID    string `json:"mqtt_client_id,omitempty"`
+	MQTTUsername    string `json:"mqtt_username,omitempty"`
+	MQTTPassword    string `json:"mqtt_password,omitempty"`
+	MQTTTopicPrefix string `json:"mqtt_topic_prefix,omitempty"`
+	MQTTQoS         byte   `json:"mqtt_qos,omitempty"`
+	MQTTRetain      bool   `json:"mqtt_retain,omitempty"`
+
+	// influx
+	InfluxAddr   string `json:"influx_addr,omitempty"`
+	InfluxOrg    string `json:"influx_org,omitempty"`
+	InfluxBucket string `json:"influx_bucket,omitempty"`
+	InfluxToken  string `json:"influx_token,omitempty"`
+}
+
+// MQTTBridgeConfig configures the optional internal/mqttbridge subsystem,
+// which republishes eventbus readings to MQTT and relays inbound Z-Wave
+// "set" commands back onto the bus's backing Z-Wave client.
+type MQTTBridgeConfig struct {
+	Enabled     bool   `json:"enabled"`
+	BrokerURL   string `json:"broker_url"`
+	ClientID    string `json:"client_id"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	TopicPrefix string `json:"topic_prefix"`
+	QoS         byte   `json:"qos"`
+	// TLSInsecureSkipVerify disables broker certificate verification; only
+	// meant for self-signed brokers on a trusted local network.
+	TLSInsecureSkipVerify bool `json:"tls_insecure_skip_verify"`
+}
+
+// RPCConfig configures the optional pkg/rpc gRPC control plane exposing
+// Thermostat and DX2W out-of-process. TLSCertFile/TLSKeyFile may be left
+// blank to serve plaintext (e.g. behind a trusted reverse proxy or VPN);
+// RequireAuth gates the setpoint, mode, DX2W setter and WriteRegister RPCs
+// behind bearer tokens scoped "thermostat" or "dx2w"; read and subscribe
+// RPCs are not gated. /auth/token mints only "thermostat"-scoped tokens.
+type RPCConfig struct {
+	Enabled     bool   `json:"enabled"`
+	HTTPAddr    string `json:"http_addr"`
+	TLSCertFile string `json:"tls_cert_file"`
+	TLSKeyFile  string `json:"tls_key_file"`
+	RequireAuth bool   `json:"require_auth"`
+}
+
+// ServerConfig configures rootserv's main listener: the middleware chain
+// (logging/recovery always apply; CORSOrigin is optional) and how it's
+// exposed beyond localhost. Leave TLSCertFile/TLSKeyFile and AutoCertDomain
+// blank to serve plain HTTP (e.g. behind a trusted reverse proxy); set
+// TLSCertFile/TLSKeyFile for a static certificate, or AutoCertDomain (with
+// AutoCertCacheDir) to obtain and renew one from Let's Encrypt instead.
+type ServerConfig struct {
+	TLSCertFile      string `json:"tls_cert_file"`
+	TLSKeyFile       string `json:"tls_key_file"`
+	AutoCertDomain   string `json:"autocert_domain"`
+	AutoCertCacheDir string `json:"autocert_cache_dir"`
+	CORSOrigin       string `json:"cors_origin"`
+}
+
+// MetricsConfig configures the dedicated pkg/metrics Prometheus scrape
+// endpoint, separate from rootserv's own "/metrics" (which serves the same
+// registry but sits behind rootserv's auth/index and main listener).
+type MetricsConfig struct {
+	HTTPAddr string `json:"http_addr"`
 }
 
 type DataLoggerConfig struct {
-	EmonCMSAddr     string `json:"emoncms_addr"`
-	EmonCMSApiKey   string `json:"emoncms_apikey"`
-	IntervalSeconds int    `json:"interval_seconds"`
+	Sinks           []SinkConfig `json:"sinks"`
+	IntervalSeconds int          `json:"interval_seconds"`
+
+	// QueueMaxPoints bounds the on-disk store-and-forward queue used when a
+	// sink is unreachable; oldest points are dropped once it's exceeded.
+	QueueMaxPoints int `json:"queue_max_points"`
 }
 
 type Config struct {
@@ -66,10 +193,20 @@ type Config struct {
 	DX2W       DX2WConfig       `json:"dx2w"`
 	Controller ControllerConfig `json:"controller"`
 	DataLogger DataLoggerConfig `json:"datalogger"`
+	Logger     LoggerConfig     `json:"logger"`
+	Auth       AuthConfig       `json:"auth"`
+	Server     ServerConfig     `json:"server"`
+	MQTTBridge MQTTBridgeConfig `json:"mqtt_bridge"`
+	Metrics    MetricsConfig    `json:"metrics"`
+	RPC        RPCConfig        `json:"rpc"`
 
 	// not loaded from file, but added here to
 	// pass to all services alongside config
-	EventBus *eventbus.Bus
+	EventBus        *eventbus.Bus
+	MetricsRegistry *sysmon.Registry
+	Authz           *authz.Issuer
+	DataDir         string
+	RootDir         string
 }
 
 func LoadFile(path string) *Config {
@@ -92,11 +229,41 @@ func LoadFile(path string) *Config {
 	if c.DataLogger.IntervalSeconds == 0 {
 		c.DataLogger.IntervalSeconds = 60
 	}
+	if c.DataLogger.QueueMaxPoints == 0 {
+		c.DataLogger.QueueMaxPoints = 10000
+	}
+	if c.Controller.ShutdownSafeLWT == 0 {
+		c.Controller.ShutdownSafeLWT = 16
+	}
 	if c.Thermostat.MaxSetpointC == 0 {
 		c.Thermostat.MaxSetpointC = 32
 	}
 	if c.Thermostat.MinSetpointC == 0 {
 		c.Thermostat.MinSetpointC = 12
 	}
+	if c.Thermostat.BatteryLowPercent == 0 {
+		c.Thermostat.BatteryLowPercent = 20
+	}
+	if c.Thermostat.EcoSetpoint == 0 {
+		c.Thermostat.EcoSetpoint = 16
+	}
+	if c.Thermostat.BoostSetpoint == 0 {
+		c.Thermostat.BoostSetpoint = c.Thermostat.MaxSetpointC
+	}
+	if c.Thermostat.BoostMaxDurationSeconds == 0 {
+		c.Thermostat.BoostMaxDurationSeconds = 2 * 60 * 60
+	}
+	if c.Auth.TokenTTLSeconds == 0 {
+		c.Auth.TokenTTLSeconds = 300
+	}
+	if c.MQTTBridge.TopicPrefix == "" {
+		c.MQTTBridge.TopicPrefix = "burlo"
+	}
+	if c.Metrics.HTTPAddr == "" {
+		c.Metrics.HTTPAddr = ":9090"
+	}
+	if c.RPC.HTTPAddr == "" {
+		c.RPC.HTTPAddr = ":9091"
+	}
 	return &c
 }
@@ -26,6 +26,10 @@ import (
 	"burlo/v2/pkg/logger"
 	"context"
 	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
@@ -33,9 +37,10 @@ var (
 	maxSupplyTemp float64 = 45 // °C
 	minSupplyTemp float64 = 27 // °C
 
-	// home envelope
-	heatLossCoefficient float64 = 0.2102 // kW/°C
-	heatLossBaseDt      float64 = 4.5    // °C
+	// home envelope: seeds for the online RLS estimator (see envelope.go),
+	// used until enough steady-state data accumulates to refine them.
+	initialHeatLossCoefficient float64 = 0.2102 // kW/°C
+	initialHeatLossBaseDt      float64 = 4.5    // °C
 
 	// hydronics
 	flowRate    float64 = 0.293 // L/s (== Kg/s)
@@ -44,8 +49,9 @@ var (
 )
 
 type Controller struct {
-	conf *config.Config
-	log  *logger.Logger
+	conf    *config.Config
+	log     *logger.Logger
+	dx2wSrv *dx2w.HistoryService
 
 	// actuators
 	pumpCtrl *pumpctrl.Controller
@@ -66,9 +72,16 @@ type Controller struct {
 	correction  float64
 	pic         *pictrl.PIController
 
+	// home envelope identification
+	envEstimator *envelopeEstimator
+
 	// validity flags
 	hasWeather    bool
 	hasThermostat bool
+
+	// metrics
+	lwtTargetGauge prometheus.Gauge
+	piCorrGauge    prometheus.Gauge
 }
 
 func (s *Controller) GetData() map[string]float64 {
@@ -84,10 +97,13 @@ func (s *Controller) GetData() map[string]float64 {
 			data["tstat_call"] = 1
 		}
 	}
+	a, b := s.envEstimator.Coefficients()
+	data["envelope_heat_loss_coef"] = a
+	data["envelope_heat_loss_offset"] = b
 	return data
 }
 
-func New(conf *config.Config) *Controller {
+func New(conf *config.Config, phidgetsService *phidgets.Manager, dx2wSrv *dx2w.HistoryService) *Controller {
 	pic := pictrl.NewPIController(1.0, 0.01).
 		WithOutputLimits(-4, 4).
 		WithDeadband(0.2).
@@ -95,21 +111,37 @@ func New(conf *config.Config) *Controller {
 		WithAntiWindup(true)
 
 	setCirculatorState := func(state bool) error {
-		return phidgets.SetDigitalOutput(conf.Phidgets.HTTPAddr, "circulator", state,
-			conf.Phidgets.CirculatorChannel, conf.Phidgets.CirculatorHubPort)
+		return phidgetsService.Circulator().Set(state)
 	}
 
 	setTargetLWT := func(tempC float64) error {
 		return dx2w.SetHotWaterMinTempC(tempC)
 	}
 
-	return &Controller{
-		conf:     conf,
-		pic:      pic,
-		lwtCtrl:  lwtctrl.NewActuator(setTargetLWT),
-		pumpCtrl: pumpctrl.NewActuator(setCirculatorState),
-		log:      logger.New("Controller"),
+	c := &Controller{
+		conf:         conf,
+		dx2wSrv:      dx2wSrv,
+		pic:          pic,
+		lwtCtrl:      lwtctrl.NewActuator(setTargetLWT),
+		pumpCtrl:     pumpctrl.NewActuator(setCirculatorState),
+		log:          logger.New("Controller"),
+		envEstimator: newEnvelopeEstimator(filepath.Join(conf.DataDir, envelopeFilename), initialHeatLossCoefficient, initialHeatLossBaseDt),
+
+		lwtTargetGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "burlo_controller_lwt_target_celsius",
+			Help: "Current leaving-water-temperature target.",
+		}),
+		piCorrGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "burlo_controller_pi_correction",
+			Help: "Current PI correction applied to the LWT target.",
+		}),
 	}
+
+	if conf.MetricsRegistry != nil {
+		conf.MetricsRegistry.MustRegister(c.lwtTargetGauge, c.piCorrGauge)
+	}
+
+	return c
 }
 
 func (s *Controller) Run(ctx context.Context) {
@@ -141,6 +173,18 @@ func (s *Controller) Run(ctx context.Context) {
 	}
 }
 
+// Shutdown forces the pump off and the LWT target down to the configured
+// safe temperature via the existing actuators, so the circulator and DX2W
+// don't keep running at the last in-flight target after the process exits.
+func (s *Controller) Shutdown(ctx context.Context) error {
+	safeLWT := s.conf.Controller.ShutdownSafeLWT
+	s.log.Info("shutdown: forcing pump off, lwt -> %.1f°C", safeLWT)
+
+	pumpErr := s.pumpCtrl.ForceOff()
+	s.lwtCtrl.SetTargetLWT(safeLWT)
+	return pumpErr
+}
+
 func (s *Controller) handleWeatherEvent(ev events.WeatherUpdate) {
 	s.outdoorTemp = ev.TemperatureC
 	s.recalculate()
@@ -167,7 +211,7 @@ func (s *Controller) recalculate() {
 		// we use setpoint instead of indoor temp to get the expected lwt/duty
 		// for steady-state at setpoint. This will slowly push conditions toward
 		// setpoint
-		s.lwtTarget, s.cdutyTarget = baselineOperatingState(s.outdoorTemp, s.setpoint)
+		s.lwtTarget, s.cdutyTarget = s.baselineOperatingState(s.outdoorTemp, s.setpoint)
 
 		// apply a small proportional boost to move towards setpoint slightly
 		// faster, and a small integral correction to account for changes to
@@ -186,6 +230,8 @@ func (s *Controller) recalculate() {
 
 	s.lwtCtrl.SetTargetLWT(s.lwtTarget)
 	s.pumpCtrl.SetDutyCycle(s.cdutyTarget)
+	s.lwtTargetGauge.Set(s.lwtTarget)
+	s.piCorrGauge.Set(s.correction)
 
 	s.log.Info("update inputs: room=%0.2f°C, setpoint=%0.2f°C, outdoor=%0.2f°C, ModeHeat=%v\n",
 		s.indoorTemp, s.setpoint, s.outdoorTemp, s.modeOn)
@@ -194,8 +240,8 @@ func (s *Controller) recalculate() {
 		s.lwtTarget, s.correction, s.cdutyTarget)
 }
 
-func baselineOperatingState(outdoorTC, indoorTC float64) (float64, float64) {
-	Qloss := expectedHeatLoadKW(indoorTC, outdoorTC)
+func (s *Controller) baselineOperatingState(outdoorTC, indoorTC float64) (float64, float64) {
+	Qloss := s.expectedHeatLoadKW(indoorTC, outdoorTC)
 
 	requiredGains := Qloss
 	supplyTC := targetSupplyTemp(indoorTC, requiredGains)
@@ -206,17 +252,82 @@ func baselineOperatingState(outdoorTC, indoorTC float64) (float64, float64) {
 	Qgain := expectedHeatGainKW(indoorTC, supplyTC)
 	circDuty := 100 * math.Min(1.0, math.Max(0.0, Qloss/Qgain))
 
+	// Feed the estimator the heat actually delivered (measured from live
+	// DX2W flow temperatures) and the real measured indoor temperature,
+	// not this function's own predicted Qloss/setpoint: training the
+	// model on its own output would make the residual ~0 and it would
+	// never learn anything from real system behavior.
+	if deliveredKW, ok := s.measuredDeliveredKW(); ok {
+		s.envEstimator.Observe(time.Now(), s.indoorTemp, s.outdoorTemp, deliveredKW, s.circulatorSteadyOn())
+	}
+
 	return supplyTC, circDuty
 }
 
-func expectedHeatLoadKW(roomTC, outdoorTC float64) float64 {
+// measuredDeliveredKW computes the heat currently being delivered by the
+// heat pump from its live exiting/return water temperatures, the same
+// flowRate/h2oConst energy-balance formula used by expectedHeatGainKW. ok
+// is false if either register is currently erroring or has no reading yet.
+func (s *Controller) measuredDeliveredKW() (float64, bool) {
+	exitingC, ok := s.latestRegisterC("hp_exiting_water_temp")
+	if !ok {
+		return 0, false
+	}
+	returnC, ok := s.latestRegisterC("return_water_temp")
+	if !ok {
+		return 0, false
+	}
+	return flowRate * h2oConst * (exitingC - returnC), true
+}
+
+// latestRegisterC returns the most recent successfully-read value of a
+// DX2W register, converted from the °F the hardware reports in to °C.
+func (s *Controller) latestRegisterC(name string) (float64, bool) {
+	entry, ok := s.dx2wSrv.LatestAll()[name]
+	if !ok || entry.Error != "" || entry.Value == nil {
+		return 0, false
+	}
+	tempF, ok := anyAsFloat64(entry.Value)
+	if !ok {
+		return 0, false
+	}
+	return (tempF - 32) * 5 / 9, true
+}
+
+// circulatorSteadyOn reports whether the circulator has been continuously
+// on for at least steadyStateWindow: a sample taken while it only just
+// started, or cycled off partway through the window, doesn't reflect a
+// true energy balance and would corrupt the fit.
+func (s *Controller) circulatorSteadyOn() bool {
+	pct, err := s.dx2wSrv.PercentOn("hp_circulator", steadyStateWindow)
+	return err == nil && pct >= 100
+}
+
+func anyAsFloat64(v any) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int16:
+		return float64(val), true
+	case uint16:
+		return float64(val), true
+	}
+	return 0, false
+}
+
+func (s *Controller) expectedHeatLoadKW(roomTC, outdoorTC float64) float64 {
 	dT := roomTC - outdoorTC
 
 	if dT < 2.5 {
 		return 0
 	}
 	minKW := 0.35
-	return math.Max(heatLossCoefficient*(dT-heatLossBaseDt), minKW)
+	a, b := s.envEstimator.Coefficients()
+	return math.Max(a*dT+b, minKW)
 }
 
 func expectedHeatGainKW(roomTC, supplyTC float64) float64 {
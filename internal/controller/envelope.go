@@ -0,0 +1,180 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"burlo/v2/pkg/logger"
+	"encoding/json"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	envelopeFilename = "envelope_model.json"
+
+	// rlsLambda is the RLS forgetting factor: closer to 1 means the model
+	// trusts older data more and adapts more slowly.
+	rlsLambda = 0.995
+
+	// minHeatLossCoef keeps the estimated slope physically sane (heat loss
+	// must increase with dT) even if a bad batch of samples pulls it low.
+	minHeatLossCoef = 0.02 // kW/°C
+
+	// A sample is only used to update the model once indoor temperature has
+	// stayed within steadyStateDT for steadyStateWindow: otherwise we're
+	// mid-transient (thermostat just changed, door left open, etc.) and the
+	// energy-balance assumption behind the regression doesn't hold.
+	steadyStateDT     = 0.15 // °C
+	steadyStateWindow = 20 * time.Minute
+)
+
+// envelopeState is the RLS model persisted to disk: Q_delivered ≈ A*dT + B,
+// where dT = indoor - outdoor.
+type envelopeState struct {
+	Theta [2]float64    `json:"theta"`
+	P     [2][2]float64 `json:"p"`
+}
+
+// envelopeEstimator tracks the home's heat-loss coefficient (A) and baseline
+// offset (B) online via recursive least squares with a forgetting factor,
+// replacing the fixed heatLossCoefficient/heatLossBaseDt constants with a
+// model that adapts to the actual building as seasons and occupancy change.
+type envelopeEstimator struct {
+	mu    sync.Mutex
+	theta [2]float64
+	p     [2][2]float64
+	path  string
+	log   *logger.Logger
+
+	haveSample   bool
+	sampleIndoor float64
+	sampleAt     time.Time
+}
+
+// newEnvelopeEstimator seeds the model from the previous fixed constants (so
+// behavior is unchanged until enough steady-state data accumulates) and
+// restores a persisted model from path if one exists.
+func newEnvelopeEstimator(path string, initialCoef, initialBaseDt float64) *envelopeEstimator {
+	e := &envelopeEstimator{
+		path:  path,
+		log:   logger.New("EnvelopeModel"),
+		theta: [2]float64{initialCoef, -initialCoef * initialBaseDt},
+		p:     [2][2]float64{{1, 0}, {0, 1}},
+	}
+	e.load()
+	return e
+}
+
+// Coefficients returns the current (A, B) such that Q_delivered ≈ A*dT + B.
+func (e *envelopeEstimator) Coefficients() (float64, float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.theta[0], e.theta[1]
+}
+
+// Observe feeds one (indoor, outdoor, deliveredKW) sample, updating the
+// model only if the indoor temperature has been essentially flat and the
+// circulator has run continuously since the last sample window.
+func (e *envelopeEstimator) Observe(now time.Time, indoorTC, outdoorTC, deliveredKW float64, circulatorOn bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	steady := circulatorOn && e.haveSample &&
+		now.Sub(e.sampleAt) >= steadyStateWindow &&
+		math.Abs(indoorTC-e.sampleIndoor) <= steadyStateDT
+
+	if !e.haveSample || now.Sub(e.sampleAt) >= steadyStateWindow {
+		e.sampleIndoor = indoorTC
+		e.sampleAt = now
+		e.haveSample = true
+	}
+
+	if !steady {
+		return
+	}
+
+	e.update(indoorTC-outdoorTC, deliveredKW)
+	e.persist()
+}
+
+// update performs one RLS step with regressor phi = [dT, 1] and target y.
+func (e *envelopeEstimator) update(dT, y float64) {
+	phi := [2]float64{dT, 1}
+
+	Pphi := [2]float64{
+		e.p[0][0]*phi[0] + e.p[0][1]*phi[1],
+		e.p[1][0]*phi[0] + e.p[1][1]*phi[1],
+	}
+	denom := rlsLambda + phi[0]*Pphi[0] + phi[1]*Pphi[1]
+	if denom == 0 {
+		return
+	}
+	gain := [2]float64{Pphi[0] / denom, Pphi[1] / denom}
+
+	yhat := e.theta[0]*phi[0] + e.theta[1]*phi[1]
+	residual := y - yhat
+
+	e.theta[0] += gain[0] * residual
+	e.theta[1] += gain[1] * residual
+	if e.theta[0] < minHeatLossCoef {
+		e.theta[0] = minHeatLossCoef
+	}
+
+	var newP [2][2]float64
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			newP[i][j] = (e.p[i][j] - gain[i]*(phi[0]*e.p[0][j]+phi[1]*e.p[1][j])) / rlsLambda
+		}
+	}
+	e.p = newP
+}
+
+func (e *envelopeEstimator) persist() {
+	tmpPath := e.path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		e.log.Error("failed to persist envelope model: %v", err)
+		return
+	}
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(envelopeState{Theta: e.theta, P: e.p}); err != nil {
+		e.log.Error("failed to encode envelope model: %v", err)
+		file.Close()
+		return
+	}
+	file.Close()
+	os.Rename(tmpPath, e.path)
+}
+
+func (e *envelopeEstimator) load() {
+	file, err := os.Open(e.path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	var state envelopeState
+	if err := json.NewDecoder(file).Decode(&state); err != nil {
+		e.log.Error("failed to decode envelope model: %v", err)
+		return
+	}
+	e.theta = state.Theta
+	e.p = state.P
+	e.log.Info("restored envelope model: A=%.4f B=%.4f", e.theta[0], e.theta[1])
+}
@@ -22,7 +22,7 @@ import (
 )
 
 type PIController struct {
-	Kp, Ki      float64
+	Kp, Ki, Kd  float64
 	intErr      float64
 	OutputMin   float64
 	OutputMax   float64
@@ -30,11 +30,26 @@ type PIController struct {
 	DecayFactor float64 // range [0,1]
 	AntiWindup  bool
 
+	// Tf is the derivative filter's time constant in seconds: the
+	// derivative runs through a first-order low-pass (alpha = dt/(Tf+dt))
+	// so measurement noise isn't amplified into the output. Tf==0 disables
+	// filtering (alpha=1, the raw per-step derivative is used as-is).
+	Tf float64
+
+	// Tt is the back-calculation tracking time constant in seconds, used
+	// by the anti-windup correction below. Tt<=0 derives one from the
+	// gains (Tt ≈ sqrt(Ti*Td)) on every Update.
+	Tt float64
+
 	log      *logger.Logger
 	lastTime time.Time
+
+	lastMeasurement float64
+	haveLast        bool
+	filteredDeriv   float64
 }
 
-// Update returns the PI output in °C adjustment
+// Update returns the PID output in °C adjustment
 func (pi *PIController) Update(setpoint, measurement float64) float64 {
 	now := time.Now()
 	dt := now.Sub(pi.lastTime).Seconds()
@@ -67,28 +82,85 @@ func (pi *PIController) Update(setpoint, measurement float64) float64 {
 		pi.intErr *= math.Pow(pi.DecayFactor, dt)
 	}
 
+	// --- Derivative term (on measurement, filtered, to avoid setpoint kick) ---
+	deriv := pi.filteredDeriv
+	if dt > 0 {
+		rawDeriv := -(measurement - pi.lastMeasurement) / dt
+		if !pi.haveLast {
+			pi.filteredDeriv = rawDeriv
+		} else {
+			alpha := 1.0
+			if pi.Tf > 0 {
+				alpha = dt / (pi.Tf + dt)
+			}
+			pi.filteredDeriv += alpha * (rawDeriv - pi.filteredDeriv)
+		}
+		pi.lastMeasurement = measurement
+		pi.haveLast = true
+		deriv = pi.filteredDeriv
+	}
+
 	// --- Compute raw output ---
-	output := pi.Kp*err + pi.Ki*pi.intErr
+	rawOutput := pi.Kp*err + pi.Ki*pi.intErr + pi.Kd*deriv
 
-	// --- Clamp and optional anti-windup ---
-	clamped := false
+	// --- Clamp and optional back-calculation anti-windup ---
+	output := rawOutput
 	if output > pi.OutputMax {
 		output = pi.OutputMax
-		clamped = true
 	} else if output < pi.OutputMin {
 		output = pi.OutputMin
-		clamped = true
 	}
 
-	if clamped && pi.AntiWindup && dt > 0 {
-		// Roll back the last integral step to prevent windup
-		pi.intErr -= err * dt
+	if pi.AntiWindup && dt > 0 {
+		tt := pi.Tt
+		if tt <= 0 {
+			tt = backCalcTimeConstant(pi.Kp, pi.Ki, pi.Kd)
+		}
+		if tt > 0 {
+			// Feed the clamp's correction back into the integrator at rate
+			// 1/Tt, rather than undoing the last integration step; this
+			// keeps unwinding correctly under sustained saturation instead
+			// of only cancelling a single step.
+			pi.intErr += (output - rawOutput) * dt / tt
+		}
 	}
 
-	pi.log.Debug("dt=%.2fs, err=%.2f°C, intErr=%.2f, output=%.2f°C", dt, err, pi.intErr, output)
+	pi.log.Debug("dt=%.2fs, err=%.2f°C, intErr=%.2f, deriv=%.2f, output=%.2f°C", dt, err, pi.intErr, deriv, output)
 	return output
 }
 
+// backCalcTimeConstant derives a back-calculation tracking time constant
+// Tt ≈ sqrt(Ti*Td) from the controller's gains, used when Tt hasn't been
+// set explicitly via WithBackCalcTime. Ti = Kp/Ki (integral time) and
+// Td = Kd/Kp (derivative time); if either is undefined or zero (Kp, Ki or
+// Kd is zero) this falls back to whichever of Ti/Td is nonzero, or 0
+// (anti-windup correction disabled) if neither is.
+func backCalcTimeConstant(kp, ki, kd float64) float64 {
+	if kp == 0 {
+		return 0
+	}
+	switch {
+	case ki == 0 && kd == 0:
+		return 0
+	case ki == 0:
+		return kd / kp
+	case kd == 0:
+		return kp / ki
+	default:
+		return math.Sqrt((kp / ki) * (kd / kp))
+	}
+}
+
+// Reset zeroes the integrator and derivative filter state, e.g. when the
+// setpoint changes mode (lwt/pump target switch) and history should not
+// bleed into the new mode.
+func (pi *PIController) Reset() {
+	pi.intErr = 0
+	pi.filteredDeriv = 0
+	pi.haveLast = false
+	pi.lastTime = time.Time{}
+}
+
 // --- Fluent "With" setters ---
 
 func NewPIController(kp, ki float64) *PIController {
@@ -119,3 +191,18 @@ func (pi *PIController) WithAntiWindup(enabled bool) *PIController {
 	pi.AntiWindup = enabled
 	return pi
 }
+
+// WithDerivative enables the D term: kd is the derivative gain, tf is the
+// derivative filter's time constant in seconds (0 disables filtering).
+func (pi *PIController) WithDerivative(kd, tf float64) *PIController {
+	pi.Kd = kd
+	pi.Tf = tf
+	return pi
+}
+
+// WithBackCalcTime overrides the back-calculation tracking time constant
+// used by anti-windup; leave unset (or pass <= 0) to derive one from Kp/Ki/Kd.
+func (pi *PIController) WithBackCalcTime(tt float64) *PIController {
+	pi.Tt = tt
+	return pi
+}
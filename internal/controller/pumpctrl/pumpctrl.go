@@ -131,6 +131,15 @@ func (c *Controller) tick(now time.Time) {
 	c.setPump(shouldBeOn)
 }
 
+// ForceOff immediately drives the actuator off, bypassing the duty-cycle
+// scheduler and its min ON/OFF enforcement; intended for shutdown paths
+// that can't wait for the next Run tick.
+func (c *Controller) ForceOff() error {
+	c.currentOn = false
+	c.lastChange = time.Now()
+	return c.actuate(false)
+}
+
 // setPump calls actuator if state changes and respects min ON/OFF times
 func (c *Controller) setPump(on bool) {
 	if c.currentOn == on {
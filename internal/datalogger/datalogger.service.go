@@ -0,0 +1,207 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package datalogger
+
+import (
+	"burlo/v2/internal/config"
+	"burlo/v2/internal/controller"
+	"burlo/v2/internal/dx2w"
+	"burlo/v2/pkg/logger"
+	"burlo/v2/pkg/service"
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	queueFilename   = "datalogger_queue.json"
+	minFlushBackoff = time.Second
+	maxFlushBackoff = time.Minute
+)
+
+type loggerService struct {
+	sinks      []Sink
+	interval   time.Duration
+	log        *logger.Logger
+	dx2wSrv    *dx2w.HistoryService
+	controller *controller.Controller
+	queue      *diskQueue
+}
+
+func New(controller *controller.Controller, dx2wSrv *dx2w.HistoryService, appConfig *config.Config) service.Runnable {
+	log := logger.New("DataLogger")
+
+	sinks := buildSinks(appConfig.DataLogger.Sinks, log)
+	if len(sinks) == 0 {
+		log.Error("no data-logger sinks configured; queued readings will be discarded")
+	}
+
+	return &loggerService{
+		sinks:    sinks,
+		interval: time.Duration(appConfig.DataLogger.IntervalSeconds) * time.Second,
+		log:      log,
+
+		dx2wSrv:    dx2wSrv,
+		controller: controller,
+		queue: newDiskQueue(
+			filepath.Join(appConfig.DataDir, queueFilename),
+			appConfig.DataLogger.QueueMaxPoints,
+			appConfig.MetricsRegistry,
+		),
+	}
+}
+
+// buildSinks constructs one Sink per configured entry. An unknown type is
+// logged and skipped rather than treated as fatal, since the other
+// configured sinks may still be perfectly usable.
+func buildSinks(cfgs []config.SinkConfig, log *logger.Logger) []Sink {
+	var sinks []Sink
+	for _, c := range cfgs {
+		switch c.Type {
+		case "emoncms":
+			sinks = append(sinks, NewEmonCMSSink(c.EmonCMSAddr, c.EmonCMSApiKey))
+		case "mqtt":
+			sinks = append(sinks, NewMQTTSink(c.MQTTBrokerURL, c.MQTTClientID, c.MQTTUsername, c.MQTTPassword, c.MQTTTopicPrefix, c.MQTTQoS, c.MQTTRetain))
+		case "influx":
+			sinks = append(sinks, NewInfluxLineSink(c.InfluxAddr, c.InfluxOrg, c.InfluxBucket, c.InfluxToken))
+		default:
+			log.Error("unknown data-logger sink type %q, skipping", c.Type)
+		}
+	}
+	return sinks
+}
+
+var dx2wKeys = []string{
+	"BUFFER_FLOW", "BUFFER_TANK_SETPOINT", "BUFFER_TANK_TEMP", "COMPRESSOR_CALL", "HOT_WATER_MIN_TEMP",
+	"HP_CIRCULATOR", "HP_ENTERING_WATER_TEMP", "HP_EXITING_WATER_TEMP", "HP_INPUT_KW",
+	"HP_OUTPUT_KW", "MIX_WATER_TEMP", "RETURN_WATER_TEMP", "OUTSIDE_AIR_TEMP",
+}
+
+func anyAsNumber(v any) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case bool:
+		if val {
+			return 1, true
+		} else {
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+func (c *loggerService) filter(keys []string, allData map[string]dx2w.HistoryEntry) map[string]float64 {
+	result := make(map[string]float64)
+	for _, key := range keys {
+		if entry, ok := allData[strings.ToLower(key)]; ok && entry.Value != nil {
+			// Safely type-assert
+			if val, ok := anyAsNumber(entry.Value); ok {
+				result[key] = val
+			} else {
+				c.log.Error("invalid type for key %q: %T", key, entry.Value)
+			}
+		} else {
+			c.log.Error("missing or invalid data for key %q", key)
+		}
+	}
+	return result
+}
+
+func (c *loggerService) getReadings() map[string]map[string]float64 {
+	return map[string]map[string]float64{
+		"dx2w":       c.filter(dx2wKeys, c.dx2wSrv.LatestAll()),
+		"controller": c.controller.GetData(),
+	}
+}
+
+// enqueue snapshots the current readings and buffers them on disk; it never
+// talks to a sink directly, so a slow or unreachable one can't stall the
+// poll cadence.
+func (c *loggerService) enqueue() {
+	now := time.Now()
+	for node, nodeData := range c.getReadings() {
+		c.queue.Push(queuedPoint{Node: node, Timestamp: now, Data: nodeData})
+	}
+}
+
+// flushLoop drains the queue in FIFO order, fanning each reading out to
+// every configured sink. A reading is only dropped once all sinks have
+// accepted it; backoff grows exponentially while any sink is failing and
+// resets as soon as a reading clears every sink.
+func (c *loggerService) flushLoop(ctx context.Context) {
+	backoff := minFlushBackoff
+	for {
+		if c.queue.Len() == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(minFlushBackoff):
+				continue
+			}
+		}
+
+		pt := c.queue.Peek(1)[0]
+		if err := c.publishToAllSinks(ctx, pt); err != nil {
+			c.log.Error("flush failed: %v (retrying in %v, %d queued)", err, backoff, c.queue.Len())
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > maxFlushBackoff {
+				backoff = maxFlushBackoff
+			}
+			continue
+		}
+
+		backoff = minFlushBackoff
+		c.queue.Drop(1)
+	}
+}
+
+func (c *loggerService) publishToAllSinks(ctx context.Context, pt queuedPoint) error {
+	for _, sink := range c.sinks {
+		if err := sink.Publish(ctx, pt.Node, pt.Timestamp, pt.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *loggerService) Run(ctx context.Context) {
+	c.log.Info("Running...")
+	defer c.log.Info("Stopped.")
+
+	tick := time.NewTicker(c.interval)
+	defer tick.Stop()
+
+	go c.flushLoop(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			c.enqueue()
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package datalogger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// EmonCMSSink posts readings to an emoncms input API using the classic
+// node/fulljson endpoint, one HTTP request per node per reading.
+type EmonCMSSink struct {
+	addr   string
+	apiKey string
+	client *http.Client
+}
+
+func NewEmonCMSSink(addr, apiKey string) *EmonCMSSink {
+	return &EmonCMSSink{
+		addr:   addr,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *EmonCMSSink) Publish(ctx context.Context, node string, ts time.Time, fields map[string]float64) error {
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("marshal fields: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/input/post?node=%s&apikey=%s&time=%d&fulljson=%s",
+		s.addr, node, s.apiKey, ts.Unix(), string(payload))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("emoncms post: unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package datalogger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// InfluxLineSink writes readings to an InfluxDB 2.x bucket using line
+// protocol over the /api/v2/write HTTP API, one measurement per node.
+type InfluxLineSink struct {
+	addr   string
+	org    string
+	bucket string
+	token  string
+	client *http.Client
+}
+
+func NewInfluxLineSink(addr, org, bucket, token string) *InfluxLineSink {
+	return &InfluxLineSink{
+		addr:   addr,
+		org:    org,
+		bucket: bucket,
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *InfluxLineSink) Publish(ctx context.Context, node string, ts time.Time, fields map[string]float64) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var line strings.Builder
+	line.WriteString(node)
+	first := true
+	for key, val := range fields {
+		if first {
+			line.WriteByte(' ')
+			first = false
+		} else {
+			line.WriteByte(',')
+		}
+		fmt.Fprintf(&line, "%s=%v", key, val)
+	}
+	fmt.Fprintf(&line, " %d", ts.UnixNano())
+
+	url := fmt.Sprintf("%s/api/v2/write?org=%s&bucket=%s&precision=ns", s.addr, s.org, s.bucket)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(line.String()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+s.token)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("influx write: unexpected status %s", resp.Status)
+	}
+	return nil
+}
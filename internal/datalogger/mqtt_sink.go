@@ -0,0 +1,82 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package datalogger
+
+import (
+	"burlo/v2/pkg/logger"
+	"context"
+	"fmt"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// MQTTSink publishes each field of a reading to its own topic under
+// <prefix>/<node>/<key> (retained when retain is set), so off-the-shelf MQTT tooling (Home Assistant,
+// Node-RED, ...) can consume individual values with no bridging required.
+type MQTTSink struct {
+	client      mqtt.Client
+	topicPrefix string
+	qos         byte
+	retain      bool
+	log         *logger.Logger
+}
+
+// NewMQTTSink connects to brokerURL and publishes an online/offline status
+// topic, with "offline" set as the connection's LWT.
+func NewMQTTSink(brokerURL, clientID, username, password, topicPrefix string, qos byte, retain bool) *MQTTSink {
+	log := logger.New("MQTTSink")
+	statusTopic := topicPrefix + "/status"
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(brokerURL).
+		SetClientID(clientID).
+		SetUsername(username).
+		SetPassword(password).
+		SetAutoReconnect(true).
+		SetWill(statusTopic, "offline", 1, true).
+		SetOnConnectHandler(func(c mqtt.Client) {
+			log.Info("connected to %s", brokerURL)
+			c.Publish(statusTopic, 1, true, "online")
+		}).
+		SetConnectionLostHandler(func(c mqtt.Client, err error) {
+			log.Error("connection lost: %v", err)
+		})
+
+	client := mqtt.NewClient(opts)
+	if token := client.Connect(); token.Wait() && token.Error() != nil {
+		log.Error("initial connect failed: %v", token.Error())
+	}
+
+	return &MQTTSink{
+		client:      client,
+		topicPrefix: topicPrefix,
+		qos:         qos,
+		retain:      retain,
+		log:         log,
+	}
+}
+
+func (s *MQTTSink) Publish(ctx context.Context, node string, ts time.Time, fields map[string]float64) error {
+	for key, val := range fields {
+		topic := fmt.Sprintf("%s/%s/%s", s.topicPrefix, node, key)
+		token := s.client.Publish(topic, s.qos, s.retain, fmt.Sprintf("%v", val))
+		if token.Wait() && token.Error() != nil {
+			return fmt.Errorf("publish %s: %w", topic, token.Error())
+		}
+	}
+	return nil
+}
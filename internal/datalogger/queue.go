@@ -0,0 +1,170 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package datalogger
+
+import (
+	"burlo/v2/pkg/sysmon"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// queuedPoint is one pending upload: a single node's reading, buffered until
+// it can be delivered to every configured sink.
+type queuedPoint struct {
+	Node      string             `json:"node"`
+	Timestamp time.Time          `json:"timestamp"`
+	Data      map[string]float64 `json:"data"`
+}
+
+// diskQueue is a bounded, disk-backed FIFO that buffers points across sink
+// outages. It's not meant for high throughput: the whole queue is rewritten
+// to disk on every push/drop, which is fine at the datalogger's
+// once-a-minute-ish cadence.
+type diskQueue struct {
+	mu       sync.Mutex
+	path     string
+	maxItems int
+	items    []queuedPoint
+
+	depthGauge  prometheus.Gauge
+	oldestGauge prometheus.Gauge
+}
+
+// newDiskQueue opens (or creates) the queue file at path, restoring any
+// points left over from a previous run. If reg is non-nil,
+// burlo_datalogger_queue_depth and
+// burlo_datalogger_queue_oldest_pending_seconds gauges are registered on it.
+func newDiskQueue(path string, maxItems int, reg *sysmon.Registry) *diskQueue {
+	q := &diskQueue{
+		path:     path,
+		maxItems: maxItems,
+		items:    loadQueueFile(path),
+	}
+
+	if reg != nil {
+		q.depthGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "burlo_datalogger_queue_depth",
+			Help: "Number of readings buffered waiting to be sent to configured sinks.",
+		})
+		q.oldestGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "burlo_datalogger_queue_oldest_pending_seconds",
+			Help: "Age in seconds of the oldest reading still queued for delivery.",
+		})
+		reg.MustRegister(q.depthGauge, q.oldestGauge)
+	}
+
+	q.updateMetrics()
+	return q
+}
+
+// Push appends a point, dropping the oldest entries first if the queue is
+// already at its configured bound.
+func (q *diskQueue) Push(pt queuedPoint) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.items = append(q.items, pt)
+	if len(q.items) > q.maxItems {
+		q.items = q.items[len(q.items)-q.maxItems:]
+	}
+	q.persist()
+	q.updateMetrics()
+}
+
+// Peek returns up to n of the oldest queued points without removing them.
+func (q *diskQueue) Peek(n int) []queuedPoint {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if n > len(q.items) {
+		n = len(q.items)
+	}
+	return append([]queuedPoint(nil), q.items[:n]...)
+}
+
+// Drop removes the n oldest points, e.g. after they've been delivered.
+func (q *diskQueue) Drop(n int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if n > len(q.items) {
+		n = len(q.items)
+	}
+	q.items = q.items[n:]
+	q.persist()
+	q.updateMetrics()
+}
+
+func (q *diskQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// persist rewrites the queue file. Caller must hold q.mu.
+func (q *diskQueue) persist() {
+	tmpPath := q.path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return
+	}
+	enc := json.NewEncoder(file)
+	for _, it := range q.items {
+		if err := enc.Encode(it); err != nil {
+			file.Close()
+			return
+		}
+	}
+	file.Sync()
+	file.Close()
+	os.Rename(tmpPath, q.path)
+}
+
+// updateMetrics refreshes the gauges. Caller must hold q.mu.
+func (q *diskQueue) updateMetrics() {
+	if q.depthGauge == nil {
+		return
+	}
+	q.depthGauge.Set(float64(len(q.items)))
+	if len(q.items) > 0 {
+		q.oldestGauge.Set(time.Since(q.items[0].Timestamp).Seconds())
+	} else {
+		q.oldestGauge.Set(0)
+	}
+}
+
+func loadQueueFile(path string) []queuedPoint {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var items []queuedPoint
+	dec := json.NewDecoder(file)
+	for dec.More() {
+		var pt queuedPoint
+		if err := dec.Decode(&pt); err != nil {
+			break
+		}
+		items = append(items, pt)
+	}
+	return items
+}
@@ -0,0 +1,28 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package datalogger
+
+import (
+	"context"
+	"time"
+)
+
+// Sink delivers one node's reading to a downstream data store. Implementations
+// should treat a single failed Publish as retryable: the caller keeps the
+// reading queued and calls again later.
+type Sink interface {
+	Publish(ctx context.Context, node string, ts time.Time, fields map[string]float64) error
+}
@@ -0,0 +1,104 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dx2w
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var invalidMetricChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// metricName turns a register id (free-form, config-driven) into a valid
+// Prometheus metric name.
+func metricName(id string) string {
+	return "dx2w_" + invalidMetricChars.ReplaceAllString(id, "_")
+}
+
+// historyCollector implements prometheus.Collector, rendering the latest
+// value of every register plus rollups computed from the same history the
+// JSON API serves, so /metrics and /api/history never disagree.
+//
+// Descriptors are built fresh in Collect rather than fixed in Describe,
+// since the set of registers (and therefore metric names) is config-driven
+// rather than known at compile time; this makes the collector "unchecked",
+// which promhttp handles fine.
+type historyCollector struct {
+	s *HistoryService
+}
+
+func (c *historyCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+func (c *historyCollector) Collect(ch chan<- prometheus.Metric) {
+	latest := c.s.LatestAll()
+
+	for name, entry := range latest {
+		regCfg, ok := c.s.registers[name]
+		if !ok {
+			continue
+		}
+
+		status := "ok"
+		if entry.Error != "" {
+			status = "error"
+		}
+		desc := prometheus.NewDesc(metricName(name), regCfg.Description, nil, prometheus.Labels{"status": status})
+		if val, ok := toGaugeValue(entry.Value); ok {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, val)
+		}
+
+		if mean, err := c.s.Mean(name, time.Hour); err == nil {
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc(metricName(name)+"_mean_1h", regCfg.Description+" (1h mean)", nil, nil),
+				prometheus.GaugeValue, mean)
+		}
+		if median, err := c.s.Median(name, time.Hour); err == nil {
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc(metricName(name)+"_median_1h", regCfg.Description+" (1h median)", nil, nil),
+				prometheus.GaugeValue, median)
+		}
+		if pct, err := c.s.PercentOn(name, 24*time.Hour); err == nil {
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc(metricName(name)+"_percent_on_24h", regCfg.Description+" (% on, 24h)", nil, nil),
+				prometheus.GaugeValue, pct)
+		}
+	}
+}
+
+// toGaugeValue converts a register value to float64, treating bool as 1/0
+// (as PercentOn does; Mean and Median skip bool values).
+func toGaugeValue(v any) (float64, bool) {
+	if b, ok := v.(bool); ok {
+		if b {
+			return 1, true
+		}
+		return 0, true
+	}
+	return toFloat64(v)
+}
+
+// handleMetrics renders a fresh registry on every scrape rather than one
+// built once at startup, since registers come from config and can't be
+// known statically.
+func (s *HistoryService) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(&historyCollector{s: s})
+	promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
@@ -17,6 +17,8 @@ package dx2w
 
 import (
 	"burlo/v2/internal/config"
+	"burlo/v2/internal/events"
+	"burlo/v2/pkg/eventbus"
 	"burlo/v2/pkg/logger"
 	"burlo/v2/pkg/modbus"
 	"compress/gzip"
@@ -50,11 +52,46 @@ type HistoryService struct {
 	modbusConfig *modbus.Config
 	snapshotFile string
 	rootDir      string
+	dataDir      string
+	stream       *streamBroadcaster
+	evBus        *eventbus.Bus
+
+	rollupMu sync.RWMutex
+	rollup   *rollupState
+
+	// lastSuccessMu guards lastSuccess, the timestamp of the most recent
+	// register read that didn't error, used by Health to detect a Modbus
+	// link that's gone silently unresponsive.
+	lastSuccessMu sync.RWMutex
+	lastSuccess   time.Time
+}
+
+// healthStaleAfter is how long a DX2W poll cycle can go without a single
+// successful register read before Health reports the link unresponsive.
+// It is a fixed value, not derived from the configured poll group
+// intervals: a read in any group resets it, and if every group polls less
+// often than this (e.g. the 660s fallback), Health fails between polls.
+const healthStaleAfter = 10 * time.Minute
+
+// Health reports whether any register has been read successfully within
+// healthStaleAfter, for rootserv's /healthz aggregator.
+func (s *HistoryService) Health() error {
+	s.lastSuccessMu.RLock()
+	last := s.lastSuccess
+	s.lastSuccessMu.RUnlock()
+
+	if last.IsZero() {
+		return fmt.Errorf("no successful register read yet")
+	}
+	if age := time.Since(last); age > healthStaleAfter {
+		return fmt.Errorf("no successful register read in %s", age.Round(time.Second))
+	}
+	return nil
 }
 
 func New(modbusConfig *modbus.Config, appConfig *config.Config) *HistoryService {
 	sync.OnceFunc(func() {
-		ModbusClient = modbus.NewClient(context.Background(), modbusConfig)
+		ModbusClient = modbus.NewClient(context.Background(), modbusConfig, appConfig.MetricsRegistry)
 	})()
 
 	s := &HistoryService{
@@ -62,12 +99,17 @@ func New(modbusConfig *modbus.Config, appConfig *config.Config) *HistoryService
 		modbusConfig: modbusConfig,
 		history:      make(map[string][]HistoryEntry),
 		registers:    modbusConfig.Registers,
-		log:          logger.New("DX2WModbus"),
+		log:          logger.New("DX2WModbus").With("host", modbusConfig.Modbus.Host),
 		snapshotFile: filepath.Join(appConfig.DataDir, snapshotFilename),
 		rootDir:      appConfig.RootDir,
+		dataDir:      appConfig.DataDir,
+		stream:       newStreamBroadcaster(),
+		rollup:       newRollupState(),
+		evBus:        appConfig.EventBus,
 	}
 
 	s.loadFromDisk()
+	s.loadTiersFromDisk()
 	return s
 }
 
@@ -117,6 +159,7 @@ func (s *HistoryService) Run(ctx context.Context) {
 			return
 		case <-snapshotTicker.C:
 			s.saveToDisk()
+			s.rollupTiers()
 		}
 	}
 }
@@ -181,6 +224,22 @@ func (s *HistoryService) pollRegisters(names []string) {
 		s.history[name] = entries[idx:]
 		s.mu.Unlock()
 
+		s.stream.publish(StreamEvent{ID: name, Value: entry.Value, Error: entry.Error, Timestamp: entry.Timestamp})
+
+		if entry.Error == "" {
+			s.lastSuccessMu.Lock()
+			s.lastSuccess = entry.Timestamp
+			s.lastSuccessMu.Unlock()
+
+			if s.evBus != nil {
+				s.evBus.Publish(events.TopicDX2WRegister, events.DX2WRegisterUpdate{
+					Register:  name,
+					Value:     entry.Value,
+					Timestamp: entry.Timestamp,
+				})
+			}
+		}
+
 		select {
 		case <-s.ctx.Done():
 			return
@@ -292,6 +351,10 @@ func (s *HistoryService) LatestAll() map[string]HistoryEntry {
 }
 
 func (s *HistoryService) Mean(name string, interval time.Duration) (float64, error) {
+	if t := pickTier(interval); t != nil {
+		return s.tierMean(name, t.name, interval)
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -321,6 +384,10 @@ func (s *HistoryService) Mean(name string, interval time.Duration) (float64, err
 }
 
 func (s *HistoryService) PercentOn(name string, interval time.Duration) (float64, error) {
+	if t := pickTier(interval); t != nil {
+		return s.tierPercentOn(name, t.name, interval)
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -359,6 +426,10 @@ func (s *HistoryService) PercentOn(name string, interval time.Duration) (float64
 }
 
 func (s *HistoryService) Median(name string, interval time.Duration) (float64, error) {
+	if t := pickTier(interval); t != nil {
+		return s.tierMedian(name, t.name, interval)
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
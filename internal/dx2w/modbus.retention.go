@@ -0,0 +1,379 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dx2w
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// retentionTier describes one rollup level: raw samples older than 24h
+// (pollRegisters' existing trim window) only survive as these bucketed
+// aggregates, each tier coarser and longer-retained than the last.
+type retentionTier struct {
+	name   string
+	bucket time.Duration
+	retain time.Duration
+}
+
+var retentionTiers = []retentionTier{
+	{name: "1m", bucket: time.Minute, retain: 7 * 24 * time.Hour},
+	{name: "15m", bucket: 15 * time.Minute, retain: 90 * 24 * time.Hour},
+	{name: "1h", bucket: time.Hour, retain: 365 * 24 * time.Hour},
+}
+
+// bucketStat is one rolled-up window of raw samples for a register.
+type bucketStat struct {
+	Start     time.Time `json:"start"`
+	Min       float64   `json:"min"`
+	Max       float64   `json:"max"`
+	Mean      float64   `json:"mean"`
+	Count     int       `json:"count"`
+	PercentOn float64   `json:"percentOn"`
+}
+
+func tierSnapshotFile(dataDir, tierName string) string {
+	return filepath.Join(dataDir, fmt.Sprintf("dx2w_history_%s.json.gz", tierName))
+}
+
+// rollupState holds every tier's rolled-up buckets, per register, ascending
+// by Start. This in-memory slice doubles as the index (queries scan the
+// buckets by Start rather than using a separate on-disk byte-offset
+// index) since even a year of hourly buckets per register is a few KB —
+// the same tradeoff HistoryService already makes keeping all raw history
+// in memory.
+type rollupState struct {
+	buckets map[string]map[string][]bucketStat // tier -> register -> buckets
+	cursor  map[string]map[string]time.Time    // tier -> register -> start of next bucket to roll up
+}
+
+func newRollupState() *rollupState {
+	return &rollupState{
+		buckets: make(map[string]map[string][]bucketStat),
+		cursor:  make(map[string]map[string]time.Time),
+	}
+}
+
+// rollupTiers computes any newly-completed buckets for every tier and
+// register since the last call, trims each tier to its retention window,
+// and persists tiers that changed. Driven off HistoryService's existing
+// snapshot ticker.
+func (s *HistoryService) rollupTiers() {
+	now := time.Now()
+
+	s.mu.RLock()
+	snapshot := make(map[string][]HistoryEntry, len(s.history))
+	for name, entries := range s.history {
+		snapshot[name] = append([]HistoryEntry(nil), entries...)
+	}
+	s.mu.RUnlock()
+
+	s.rollupMu.Lock()
+	defer s.rollupMu.Unlock()
+
+	for _, t := range retentionTiers {
+		if s.rollup.buckets[t.name] == nil {
+			s.rollup.buckets[t.name] = make(map[string][]bucketStat)
+		}
+		if s.rollup.cursor[t.name] == nil {
+			s.rollup.cursor[t.name] = make(map[string]time.Time)
+		}
+
+		changed := false
+		for name, entries := range snapshot {
+			if len(entries) == 0 {
+				continue
+			}
+			from := s.rollup.cursor[t.name][name]
+			fresh := computeBuckets(entries, t.bucket, from, now)
+			if len(fresh) == 0 {
+				continue
+			}
+
+			s.rollup.buckets[t.name][name] = append(s.rollup.buckets[t.name][name], fresh...)
+			s.rollup.cursor[t.name][name] = fresh[len(fresh)-1].Start.Add(t.bucket)
+			s.rollup.buckets[t.name][name] = trimBuckets(s.rollup.buckets[t.name][name], now.Add(-t.retain))
+			changed = true
+		}
+
+		if changed {
+			if err := s.persistTier(t.name); err != nil {
+				s.log.Error("failed to persist %s tier: %v", t.name, err)
+			}
+		}
+	}
+}
+
+// computeBuckets groups entries into completed bucketDur-wide windows
+// starting at or after from (from being the zero Time means "from the
+// earliest available sample"), skipping any bucket not yet finished as of
+// now.
+func computeBuckets(entries []HistoryEntry, bucketDur time.Duration, from, now time.Time) []bucketStat {
+	groups := make(map[time.Time][]HistoryEntry)
+	for _, e := range entries {
+		if e.Error != "" || e.Value == nil {
+			continue
+		}
+		start := e.Timestamp.Truncate(bucketDur)
+		if !from.IsZero() && start.Before(from) {
+			continue
+		}
+		if start.Add(bucketDur).After(now) {
+			continue
+		}
+		groups[start] = append(groups[start], e)
+	}
+
+	starts := make([]time.Time, 0, len(groups))
+	for start := range groups {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	out := make([]bucketStat, 0, len(starts))
+	for _, start := range starts {
+		out = append(out, summarizeBucket(start, groups[start]))
+	}
+	return out
+}
+
+// summarizeBucket reduces a bucket's entries to min/max/mean/percentOn.
+// min/max/mean go through toGaugeValue, so bool values count as 1/0 there;
+// percentOn counts true or nonzero values, the same way PercentOn does.
+func summarizeBucket(start time.Time, entries []HistoryEntry) bucketStat {
+	stat := bucketStat{Start: start, Min: math.Inf(1), Max: math.Inf(-1)}
+
+	sum := 0.0
+	numeric := 0
+	onTotal := 0
+	onCount := 0
+	for _, e := range entries {
+		if val, ok := toGaugeValue(e.Value); ok {
+			sum += val
+			numeric++
+			stat.Min = math.Min(stat.Min, val)
+			stat.Max = math.Max(stat.Max, val)
+		}
+		switch v := e.Value.(type) {
+		case bool:
+			onTotal++
+			if v {
+				onCount++
+			}
+		case int, int16, uint16, float32, float64:
+			onTotal++
+			if n, ok := toFloat64(v); ok && n != 0 {
+				onCount++
+			}
+		}
+	}
+
+	stat.Count = len(entries)
+	if numeric > 0 {
+		stat.Mean = sum / float64(numeric)
+	} else {
+		stat.Min, stat.Max = 0, 0
+	}
+	if onTotal > 0 {
+		stat.PercentOn = 100 * float64(onCount) / float64(onTotal)
+	}
+	return stat
+}
+
+// trimBuckets drops buckets at or before cutoff, mirroring pollRegisters'
+// own history-trim logic (including its "only trims once something past
+// cutoff is found" behavior).
+func trimBuckets(buckets []bucketStat, cutoff time.Time) []bucketStat {
+	idx := 0
+	for i, b := range buckets {
+		if b.Start.After(cutoff) {
+			idx = i
+			break
+		}
+	}
+	return buckets[idx:]
+}
+
+func (s *HistoryService) persistTier(tierName string) error {
+	path := tierSnapshotFile(s.dataDir, tierName)
+	tmpPath := path + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(file)
+	enc := json.NewEncoder(gz)
+	if err := enc.Encode(s.rollup.buckets[tierName]); err != nil {
+		gz.Close()
+		file.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func (s *HistoryService) loadTiersFromDisk() {
+	s.rollupMu.Lock()
+	defer s.rollupMu.Unlock()
+
+	for _, t := range retentionTiers {
+		path := tierSnapshotFile(s.dataDir, t.name)
+		buckets, err := loadTierFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				s.log.Error("failed to load %s tier snapshot: %v", t.name, err)
+			}
+			continue
+		}
+
+		s.rollup.buckets[t.name] = buckets
+		s.rollup.cursor[t.name] = make(map[string]time.Time, len(buckets))
+		for name, bs := range buckets {
+			if len(bs) > 0 {
+				s.rollup.cursor[t.name][name] = bs[len(bs)-1].Start.Add(t.bucket)
+			}
+		}
+		s.log.Info("restored %s tier (%d registers)", t.name, len(buckets))
+	}
+}
+
+func loadTierFile(path string) (map[string][]bucketStat, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var buckets map[string][]bucketStat
+	if err := json.NewDecoder(gz).Decode(&buckets); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+// pickTier returns the finest tier that still retains samples for
+// interval, or nil if interval is short enough to answer from raw history.
+func pickTier(interval time.Duration) *retentionTier {
+	switch {
+	case interval <= 24*time.Hour:
+		return nil
+	case interval <= retentionTiers[0].retain:
+		return &retentionTiers[0]
+	case interval <= retentionTiers[1].retain:
+		return &retentionTiers[1]
+	default:
+		return &retentionTiers[2]
+	}
+}
+
+// tierMean/tierMedian/tierPercentOn mirror Mean/Median/PercentOn but read
+// from a rolled-up tier instead of raw history, for intervals longer than
+// the 24h raw retention window.
+
+func (s *HistoryService) tierMean(name, tierName string, interval time.Duration) (float64, error) {
+	s.rollupMu.RLock()
+	defer s.rollupMu.RUnlock()
+
+	cutoff := time.Now().Add(-interval)
+	sum, count := 0.0, 0
+	for _, b := range s.rollup.buckets[tierName][name] {
+		if b.Start.Before(cutoff) || b.Count == 0 {
+			continue
+		}
+		sum += b.Mean * float64(b.Count)
+		count += b.Count
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("no rolled-up values for register %q in the last %s", name, interval)
+	}
+	return sum / float64(count), nil
+}
+
+// tierMedian approximates the median as the median of per-bucket means,
+// since raw samples aren't retained at tiered resolutions.
+func (s *HistoryService) tierMedian(name, tierName string, interval time.Duration) (float64, error) {
+	s.rollupMu.RLock()
+	defer s.rollupMu.RUnlock()
+
+	cutoff := time.Now().Add(-interval)
+	var means []float64
+	for _, b := range s.rollup.buckets[tierName][name] {
+		if b.Start.Before(cutoff) || b.Count == 0 {
+			continue
+		}
+		means = append(means, b.Mean)
+	}
+	if len(means) == 0 {
+		return 0, fmt.Errorf("no rolled-up values for register %q in the last %s", name, interval)
+	}
+	sort.Float64s(means)
+	mid := len(means) / 2
+	if len(means)%2 == 0 {
+		return (means[mid-1] + means[mid]) / 2, nil
+	}
+	return means[mid], nil
+}
+
+func (s *HistoryService) tierPercentOn(name, tierName string, interval time.Duration) (float64, error) {
+	s.rollupMu.RLock()
+	defer s.rollupMu.RUnlock()
+
+	cutoff := time.Now().Add(-interval)
+	onCount, total := 0.0, 0
+	for _, b := range s.rollup.buckets[tierName][name] {
+		if b.Start.Before(cutoff) || b.Count == 0 {
+			continue
+		}
+		onCount += b.PercentOn / 100 * float64(b.Count)
+		total += b.Count
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("no rolled-up values for register %q in the last %s", name, interval)
+	}
+	return onCount / float64(total) * 100, nil
+}
+
+// bucketsForResolution returns the retained buckets for a named tier
+// ("1m", "15m", "1h"), used by the /api/history ?resolution= parameter.
+func (s *HistoryService) bucketsForResolution(id, resolution string) ([]bucketStat, error) {
+	for _, t := range retentionTiers {
+		if t.name == resolution {
+			s.rollupMu.RLock()
+			defer s.rollupMu.RUnlock()
+			return append([]bucketStat(nil), s.rollup.buckets[t.name][id]...), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown resolution %q", resolution)
+}
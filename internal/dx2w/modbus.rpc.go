@@ -0,0 +1,76 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dx2w
+
+import (
+	"context"
+
+	"burlo/v2/pkg/rpc"
+)
+
+// RPCServer adapts this package's setpoint setters and ModbusClient onto
+// rpc.DX2WServer. It holds no state of its own: every method just calls
+// through to the package-level functions/ModbusClient already used by
+// internal/controller.
+type RPCServer struct{}
+
+// NewRPCServer builds a DX2W gRPC service adapter.
+func NewRPCServer() *RPCServer {
+	return &RPCServer{}
+}
+
+func (s *RPCServer) SetOutdoorAirDesignTempC(ctx context.Context, req *rpc.FloatValue) (*rpc.Empty, error) {
+	if err := SetOutdoorAirDesignTempC(req.ValueC); err != nil {
+		return nil, err
+	}
+	return &rpc.Empty{}, nil
+}
+
+func (s *RPCServer) SetHotWaterDesignTempC(ctx context.Context, req *rpc.FloatValue) (*rpc.Empty, error) {
+	if err := SetHotWaterDesignTempC(req.ValueC); err != nil {
+		return nil, err
+	}
+	return &rpc.Empty{}, nil
+}
+
+func (s *RPCServer) SetHotWaterMinTempC(ctx context.Context, req *rpc.FloatValue) (*rpc.Empty, error) {
+	if err := SetHotWaterMinTempC(req.ValueC); err != nil {
+		return nil, err
+	}
+	return &rpc.Empty{}, nil
+}
+
+func (s *RPCServer) SetHotWaterDifferentialTempC(ctx context.Context, req *rpc.FloatValue) (*rpc.Empty, error) {
+	if err := SetHotWaterDifferentialTempC(req.ValueC); err != nil {
+		return nil, err
+	}
+	return &rpc.Empty{}, nil
+}
+
+func (s *RPCServer) ReadRegister(ctx context.Context, req *rpc.RegisterRequest) (*rpc.RegisterValue, error) {
+	val, err := ModbusClient.ReadValue(req.Register)
+	if err != nil {
+		return nil, err
+	}
+	return rpc.NewRegisterValue(val)
+}
+
+func (s *RPCServer) WriteRegister(ctx context.Context, req *rpc.WriteRegisterRequest) (*rpc.Empty, error) {
+	if err := ModbusClient.WriteValue(req.Register, req.Value); err != nil {
+		return nil, err
+	}
+	return &rpc.Empty{}, nil
+}
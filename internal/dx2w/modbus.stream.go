@@ -0,0 +1,248 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dx2w
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// StreamEvent is one register update pushed to /api/stream subscribers.
+type StreamEvent struct {
+	ID        string    `json:"id"`
+	Value     any       `json:"value,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// streamSubscription is the subscribe message clients send: empty IDs means
+// "everything", OnChangeOnly suppresses repeats of the same value/error.
+type streamSubscription struct {
+	IDs          []string `json:"ids"`
+	OnChangeOnly bool     `json:"onChangeOnly"`
+}
+
+func (sub streamSubscription) wants(id string) bool {
+	if len(sub.IDs) == 0 {
+		return true
+	}
+	for _, want := range sub.IDs {
+		if want == id {
+			return true
+		}
+	}
+	return false
+}
+
+// streamClientBuffer bounds how far a slow consumer can fall behind before
+// its oldest queued update is dropped in favor of the newest one.
+const streamClientBuffer = 32
+
+// streamClient is one /api/stream subscriber's mailbox.
+type streamClient struct {
+	sub  streamSubscription
+	ch   chan StreamEvent
+	last map[string]StreamEvent // last event sent to this client, per id
+}
+
+func newStreamClient(sub streamSubscription) *streamClient {
+	return &streamClient{
+		sub:  sub,
+		ch:   make(chan StreamEvent, streamClientBuffer),
+		last: make(map[string]StreamEvent),
+	}
+}
+
+// accept reports whether ev should be delivered to this client given its
+// subscription, recording it as "last sent" if so.
+func (c *streamClient) accept(ev StreamEvent) bool {
+	if !c.sub.wants(ev.ID) {
+		return false
+	}
+	if c.sub.OnChangeOnly {
+		if prev, ok := c.last[ev.ID]; ok && prev.Value == ev.Value && prev.Error == ev.Error {
+			return false
+		}
+	}
+	c.last[ev.ID] = ev
+	return true
+}
+
+// send delivers ev, dropping the oldest queued event first if the client's
+// mailbox is full rather than blocking the broadcaster on a slow consumer.
+func (c *streamClient) send(ev StreamEvent) {
+	select {
+	case c.ch <- ev:
+		return
+	default:
+	}
+	select {
+	case <-c.ch:
+	default:
+	}
+	select {
+	case c.ch <- ev:
+	default:
+	}
+}
+
+// streamBroadcaster fans out register updates to every connected
+// /api/stream client, filtering per-client by its own subscription.
+type streamBroadcaster struct {
+	mu      sync.Mutex
+	clients map[*streamClient]bool
+}
+
+func newStreamBroadcaster() *streamBroadcaster {
+	return &streamBroadcaster{clients: make(map[*streamClient]bool)}
+}
+
+func (b *streamBroadcaster) register(sub streamSubscription) *streamClient {
+	c := newStreamClient(sub)
+	b.mu.Lock()
+	b.clients[c] = true
+	b.mu.Unlock()
+	return c
+}
+
+func (b *streamBroadcaster) unregister(c *streamClient) {
+	b.mu.Lock()
+	delete(b.clients, c)
+	b.mu.Unlock()
+}
+
+func (b *streamBroadcaster) publish(ev StreamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for c := range b.clients {
+		if c.accept(ev) {
+			c.send(ev)
+		}
+	}
+}
+
+// handleAPIStream serves live register updates: a plain GET gets Server-Sent
+// Events (the default, easiest for the built-in web UI), while a websocket
+// upgrade request gets a websocket carrying the same events plus an initial
+// subscription message.
+func (s *HistoryService) handleAPIStream(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		s.serveStreamWS(w, r)
+		return
+	}
+	s.serveStreamSSE(w, r)
+}
+
+// serveStreamSSE subscribes via query params (?ids=a,b&onChangeOnly=true)
+// since an EventSource connection can't carry a request body.
+func (s *HistoryService) serveStreamSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	var sub streamSubscription
+	if ids := r.URL.Query().Get("ids"); ids != "" {
+		sub.IDs = strings.Split(ids, ",")
+	}
+	sub.OnChangeOnly = r.URL.Query().Get("onChangeOnly") == "true"
+
+	client := s.stream.register(sub)
+	defer s.stream.unregister(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev := <-client.ch:
+			data, err := json.Marshal(ev)
+			if err != nil {
+				s.log.Error("failed to marshal stream event: %v", err)
+				continue
+			}
+			if _, err := w.Write([]byte("data: " + string(data) + "\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+var streamUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return false
+		}
+		return strings.Contains(origin, "localhost") || strings.Contains(origin, r.Host)
+	},
+}
+
+// serveStreamWS expects the client's first websocket message to be the
+// subscription (e.g. {"ids":["..."],"onChangeOnly":true}), then streams
+// StreamEvents until the connection closes.
+func (s *HistoryService) serveStreamWS(w http.ResponseWriter, r *http.Request) {
+	ws, err := streamUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.log.Error("failed to upgrade /api/stream websocket: %v", err)
+		return
+	}
+	defer ws.Close()
+
+	var sub streamSubscription
+	if err := ws.ReadJSON(&sub); err != nil {
+		s.log.Error("failed to read /api/stream subscription: %v", err)
+		return
+	}
+
+	client := s.stream.register(sub)
+	defer s.stream.unregister(client)
+
+	// The only thing we expect after the subscription is the client closing
+	// the connection; watch for that so we can stop writing promptly.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case ev := <-client.ch:
+			if err := ws.WriteJSON(ev); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -40,6 +40,8 @@ func (s *HistoryService) NewServeMux() http.Handler {
 	mux.HandleFunc("/api/values", s.handleAPIValues)
 	mux.HandleFunc("/api/write", s.handleAPIWrite)
 	mux.HandleFunc("/api/history", s.handleAPIHistory)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/api/stream", s.handleAPIStream)
 
 	// Serve the www directory
 	mux.Handle("/", http.FileServer(http.Dir("internal/dx2w/www")))
@@ -78,7 +80,10 @@ func (s *HistoryService) handleAPIValues(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// handleAPIHistory returns all history entries for a given register.
+// handleAPIHistory returns history for a given register. By default it
+// returns raw samples from the last 24h. Passing ?resolution=1m|15m|1h
+// returns rolled-up buckets from that retention tier instead, for callers
+// charting longer time ranges than raw history retains.
 func (s *HistoryService) handleAPIHistory(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 	if id == "" {
@@ -87,9 +92,64 @@ func (s *HistoryService) handleAPIHistory(w http.ResponseWriter, r *http.Request
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(s.ListAll(id)); err != nil {
-		s.log.Error("failed to encode history for id %s: %v", id, err)
+
+	resolution := r.URL.Query().Get("resolution")
+	if resolution == "" {
+		if err := json.NewEncoder(w).Encode(s.ListAll(id)); err != nil {
+			s.log.Error("failed to encode history for id %s: %v", id, err)
+		}
+		return
+	}
+
+	buckets, err := s.bucketsForResolution(id, resolution)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		s.log.Error("handleAPIHistory: %v", err)
+		return
+	}
+
+	from, to, ok := parseTimeRange(r)
+	if ok {
+		buckets = filterBucketsByRange(buckets, from, to)
+	}
+
+	if err := json.NewEncoder(w).Encode(buckets); err != nil {
+		s.log.Error("failed to encode rolled-up history for id %s: %v", id, err)
+	}
+}
+
+// parseTimeRange reads RFC3339 ?from= and ?to= query params. If only from
+// is given, to defaults to now. ok is false when from is absent or invalid.
+func parseTimeRange(r *http.Request) (from, to time.Time, ok bool) {
+	fromStr := r.URL.Query().Get("from")
+	if fromStr == "" {
+		return time.Time{}, time.Time{}, false
+	}
+	from, err := time.Parse(time.RFC3339, fromStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	to = time.Now()
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		parsed, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
 	}
+	return from, to, true
+}
+
+func filterBucketsByRange(buckets []bucketStat, from, to time.Time) []bucketStat {
+	out := make([]bucketStat, 0, len(buckets))
+	for _, b := range buckets {
+		if b.Start.Before(from) || b.Start.After(to) {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
 }
 
 func (s *HistoryService) handleAPIWrite(w http.ResponseWriter, r *http.Request) {
@@ -155,6 +215,8 @@ func (s *HistoryService) handleAPIWrite(w http.ResponseWriter, r *http.Request)
 		s.mu.Lock()
 		s.history[req.ID] = append(s.history[req.ID], entry)
 		s.mu.Unlock()
+
+		s.stream.publish(StreamEvent{ID: req.ID, Value: entry.Value, Error: entry.Error, Timestamp: entry.Timestamp})
 	}()
 
 	s.log.Info("updated modbus register: %+v", req)
@@ -23,6 +23,19 @@ import (
 var (
 	TopicWeather    eventbus.Topic = "weather"
 	TopicThermostat eventbus.Topic = "thermostat"
+	TopicModeEco    eventbus.Topic = "mode_eco"
+	TopicModeBoost  eventbus.Topic = "mode_boost"
+
+	// TopicZWaveRaw and TopicDX2WRegister carry every raw Z-Wave value
+	// update and DX2W register read, unfiltered by node or consumer, for
+	// subsystems (e.g. mqttbridge) that want the full stream rather than
+	// the thermostat's curated ThermostatUpdate view.
+	TopicZWaveRaw     eventbus.Topic = "zwave_raw"
+	TopicDX2WRegister eventbus.Topic = "dx2w_register"
+
+	// TopicPhidgetsConn carries PhidgetsConnEvent whenever the Phidget22
+	// network server connection comes up or goes down.
+	TopicPhidgetsConn eventbus.Topic = "phidgets_conn"
 )
 
 type WeatherUpdate struct {
@@ -34,9 +47,53 @@ type WeatherUpdate struct {
 }
 
 type ThermostatUpdate struct {
-	TemperatureC float64
-	SetpointC    float64
-	Humidity     float64
-	Mode         int
-	State        int
+	TemperatureC          float64
+	SetpointC             float64
+	Humidity              float64
+	Mode                  int
+	State                 int
+	Battery               float64
+	BatteryLow            bool
+	Valve                 float64
+	EcoSetpointC          float64
+	BoostRemainingSeconds float64
+	ZWaveOnline           bool
+}
+
+// ModeEcoEvent is published whenever the thermostat enters or leaves Eco
+// (Energy Save Heating) mode, so schedulers don't need to poll ThermostatUpdate.
+type ModeEcoEvent struct {
+	Active bool
+}
+
+// ModeBoostEvent is published whenever the thermostat enters or leaves
+// Boost mode.
+type ModeBoostEvent struct {
+	Active bool
+}
+
+// ZWaveRawUpdate is a single Z-Wave value update, published for every node
+// the controller sees (not just the thermostat's), so external consumers
+// like mqttbridge can mirror the whole network rather than one device.
+type ZWaveRawUpdate struct {
+	NodeID           int
+	CommandClassName string
+	PropertyName     string
+	Value            any
+	Timestamp        time.Time
+}
+
+// DX2WRegisterUpdate is a single DX2W Modbus register read, published on
+// every poll that read without error, regardless of whether any in-process
+// consumer cares about it.
+type DX2WRegisterUpdate struct {
+	Register  string
+	Value     any
+	Timestamp time.Time
+}
+
+// PhidgetsConnEvent is published whenever the Phidget22 network server
+// connection comes up or goes down.
+type PhidgetsConnEvent struct {
+	Connected bool
 }
@@ -0,0 +1,203 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package mqttbridge republishes the controller's eventbus readings
+// (raw Z-Wave value updates, DX2W register reads) to MQTT as retained
+// messages, and relays inbound Z-Wave "set" commands back onto the bus's
+// backing Z-Wave client. This makes the controller a first-class citizen
+// of existing MQTT-based home-automation stacks without coupling the
+// core eventbus/zwave/dx2w packages to any specific consumer.
+//
+// This lives under internal/, not pkg/, because it necessarily depends on
+// internal/events' topic/payload shapes to know what to bridge; pkg/
+// packages in this repo (zwavejsws, modbus, agentrpc, ...) never import
+// internal/.
+package mqttbridge
+
+import (
+	"burlo/v2/internal/config"
+	"burlo/v2/internal/events"
+	"burlo/v2/pkg/eventbus"
+	"burlo/v2/pkg/logger"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// SetValueFunc dispatches an inbound MQTT "set" command to the Z-Wave
+// client, matching zwavejsws.Client.SetValue's signature so that method
+// can be passed directly.
+type SetValueFunc func(nodeID, commandClass int, property string, value any) error
+
+// payload is the JSON body published for every bridged reading.
+type payload struct {
+	Value any       `json:"value"`
+	Unit  string    `json:"unit,omitempty"`
+	Ts    time.Time `json:"ts"`
+}
+
+// Bridge subscribes to events.TopicZWaveRaw and events.TopicDX2WRegister
+// and republishes each as a retained MQTT message under
+// "<prefix>/zwave/<nodeId>/<commandClassName>/<propertyName>" and
+// "<prefix>/dx2w/<register>". It also subscribes to
+// "<prefix>/zwave/<nodeId>/<commandClass>/<property>/set" and relays
+// inbound writes to setValue.
+type Bridge struct {
+	conf     config.MQTTBridgeConfig
+	evBus    *eventbus.Bus
+	setValue SetValueFunc
+	client   mqtt.Client
+	log      *logger.Logger
+}
+
+// NewBridge builds a Bridge from conf.MQTTBridge and conf.EventBus.
+// setValue is called for inbound "set" commands; pass nil if there is no
+// Z-Wave client to write back to (readings still get bridged).
+func NewBridge(conf *config.Config, setValue SetValueFunc) *Bridge {
+	return &Bridge{
+		conf:     conf.MQTTBridge,
+		evBus:    conf.EventBus,
+		setValue: setValue,
+		log:      logger.New("MQTTBridge"),
+	}
+}
+
+func (b *Bridge) Run(ctx context.Context) {
+	if !b.conf.Enabled {
+		b.log.Info("disabled, not starting")
+		return
+	}
+	if b.evBus == nil {
+		b.log.Error("no eventbus configured, not starting")
+		return
+	}
+
+	statusTopic := b.conf.TopicPrefix + "/status"
+	setTopicFilter := b.conf.TopicPrefix + "/zwave/+/+/+/set"
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(b.conf.BrokerURL).
+		SetClientID(b.conf.ClientID).
+		SetUsername(b.conf.Username).
+		SetPassword(b.conf.Password).
+		SetAutoReconnect(true).
+		SetWill(statusTopic, "offline", b.conf.QoS, true).
+		SetOnConnectHandler(func(c mqtt.Client) {
+			b.log.Info("connected to %s", b.conf.BrokerURL)
+			c.Publish(statusTopic, b.conf.QoS, true, "online")
+			if token := c.Subscribe(setTopicFilter, b.conf.QoS, b.handleSet); token.Wait() && token.Error() != nil {
+				b.log.Error("subscribe %s failed: %v", setTopicFilter, token.Error())
+			}
+		}).
+		SetConnectionLostHandler(func(c mqtt.Client, err error) {
+			b.log.Error("connection lost: %v", err)
+		})
+
+	if b.conf.TLSInsecureSkipVerify {
+		opts.SetTLSConfig(&tls.Config{InsecureSkipVerify: true})
+	}
+
+	b.client = mqtt.NewClient(opts)
+	if token := b.client.Connect(); token.Wait() && token.Error() != nil {
+		b.log.Error("initial connect failed: %v", token.Error())
+	}
+	defer b.client.Disconnect(250)
+
+	zwaveCh, unsubZWave := b.evBus.Subscribe(ctx, events.TopicZWaveRaw, false)
+	defer unsubZWave()
+	dx2wCh, unsubDX2W := b.evBus.Subscribe(ctx, events.TopicDX2WRegister, false)
+	defer unsubDX2W()
+
+	b.log.Info("bridging eventbus to mqtt at %s (prefix %q)", b.conf.BrokerURL, b.conf.TopicPrefix)
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.log.Info("stopping")
+			return
+
+		case ev, ok := <-zwaveCh:
+			if !ok {
+				return
+			}
+			update, ok := ev.(events.ZWaveRawUpdate)
+			if !ok {
+				continue
+			}
+			topic := fmt.Sprintf("%s/zwave/%d/%s/%s", b.conf.TopicPrefix, update.NodeID, update.CommandClassName, update.PropertyName)
+			b.publish(topic, payload{Value: update.Value, Ts: update.Timestamp})
+
+		case ev, ok := <-dx2wCh:
+			if !ok {
+				return
+			}
+			update, ok := ev.(events.DX2WRegisterUpdate)
+			if !ok {
+				continue
+			}
+			topic := fmt.Sprintf("%s/dx2w/%s", b.conf.TopicPrefix, update.Register)
+			b.publish(topic, payload{Value: update.Value, Ts: update.Timestamp})
+		}
+	}
+}
+
+func (b *Bridge) publish(topic string, p payload) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		b.log.Error("marshal %s: %v", topic, err)
+		return
+	}
+	token := b.client.Publish(topic, b.conf.QoS, true, data)
+	if token.Wait() && token.Error() != nil {
+		b.log.Error("publish %s: %v", topic, token.Error())
+	}
+}
+
+// handleSet translates an inbound
+// "<prefix>/zwave/<nodeId>/<commandClass>/<property>/set" message into a
+// Z-Wave SetValue call. The payload is the raw value, as JSON.
+func (b *Bridge) handleSet(_ mqtt.Client, msg mqtt.Message) {
+	parts := strings.Split(msg.Topic(), "/")
+	if len(parts) < 4 || parts[len(parts)-1] != "set" {
+		b.log.Error("unexpected set topic %q", msg.Topic())
+		return
+	}
+	property := parts[len(parts)-2]
+	commandClass, ccErr := strconv.Atoi(parts[len(parts)-3])
+	nodeID, nodeErr := strconv.Atoi(parts[len(parts)-4])
+	if ccErr != nil || nodeErr != nil {
+		b.log.Error("set topic %q: node id / command class must be numeric", msg.Topic())
+		return
+	}
+
+	var value any
+	if err := json.Unmarshal(msg.Payload(), &value); err != nil {
+		value = string(msg.Payload())
+	}
+
+	if b.setValue == nil {
+		b.log.Error("no Z-Wave client configured, dropping set for node %d/%s", nodeID, property)
+		return
+	}
+	if err := b.setValue(nodeID, commandClass, property, value); err != nil {
+		b.log.Error("SetValue(%d, %d, %s) failed: %v", nodeID, commandClass, property, err)
+	}
+}
@@ -17,64 +17,90 @@ package phidgets
 
 import (
 	"burlo/v2/internal/config"
+	"burlo/v2/internal/events"
 	"burlo/v2/pkg/logger"
+	"burlo/v2/pkg/phidget22"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
-	"os/exec"
-	"time"
+	"sync/atomic"
 )
 
-// Path to your Python service script
-const pythonScript = "internal/phidgets/phidgets.service.py"
-
+// Manager owns the connection to the Phidget22 network server and exposes
+// the channels the rest of the app drives, replacing the old Python
+// subprocess + webhook bridge.
 type Manager struct {
-	conf *config.Config
-	log  *logger.Logger
+	conf   *config.Config
+	client *phidget22.Client
+	log    *logger.Logger
+
+	connected atomic.Bool
 }
 
 func New(conf *config.Config) *Manager {
-	return &Manager{
-		conf: conf,
-		log:  logger.New("Phidgets  "),
+	m := &Manager{
+		conf:   conf,
+		client: phidget22.NewClient(conf.Phidgets.ServerAddr),
+		log:    logger.New("Phidgets  "),
 	}
+	m.client.OnConnState(m.onConnState)
+	return m
 }
 
 func (m *Manager) Run(ctx context.Context) {
-	for {
-		// If context is canceled, exit loop (shutdown requested)
-		select {
-		case <-ctx.Done():
-			m.log.Info("Stopped")
-			return
-		default:
-		}
-		cmd := exec.CommandContext(ctx, "python3", pythonScript, m.conf.Phidgets.HTTPAddr)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	m.log.Info("starting")
+	m.client.Run(ctx)
+	m.log.Info("stopped")
+}
+
+func (m *Manager) onConnState(connected bool) {
+	m.connected.Store(connected)
+	if connected {
+		m.log.Info("connected to phidget22 network server")
+	} else {
+		m.log.Error("lost connection to phidget22 network server")
+	}
+	if m.conf.EventBus != nil {
+		m.conf.EventBus.Publish(events.TopicPhidgetsConn, events.PhidgetsConnEvent{Connected: connected})
+	}
+}
 
-		m.log.Info("Running...")
+// Health reports whether the Phidget22 network server connection is up,
+// for rootserv's /healthz aggregator.
+func (m *Manager) Health() error {
+	if !m.connected.Load() {
+		return fmt.Errorf("phidget22 network server disconnected")
+	}
+	return nil
+}
 
-		err := cmd.Start()
-		if err != nil {
-			m.log.Error("Failed to start python cmd: %v", err)
-			time.Sleep(5 * time.Second)
-			continue
-		}
+// Circulator returns the digital output channel driving the circulator
+// pump, wired from config, so callers don't need to know its channel/hub
+// port.
+func (m *Manager) Circulator() *phidget22.DigitalOutChannel {
+	return m.client.DigitalOut(m.conf.Phidgets.CirculatorChannel, m.conf.Phidgets.CirculatorHubPort)
+}
 
-		// Wait until it stops or context is canceled
-		err = cmd.Wait()
+// DigitalOut returns a handle to an arbitrary digital output channel.
+func (m *Manager) DigitalOut(channel, hubPort int) *phidget22.DigitalOutChannel {
+	return m.client.DigitalOut(channel, hubPort)
+}
 
-		if err != nil {
-			m.log.Error("cmd exited with error: %v", err)
-		}
-		m.log.Info("Restarting")
-		time.Sleep(2 * time.Second)
-	}
+// VoltageOut returns a handle to an arbitrary voltage output channel.
+func (m *Manager) VoltageOut(channel, hubPort int) *phidget22.VoltageOutChannel {
+	return m.client.VoltageOut(channel, hubPort)
+}
+
+// DigitalIn returns a handle to an arbitrary digital input channel, whose
+// Events channel replaces the old webhook mechanism.
+func (m *Manager) DigitalIn(channel, hubPort int) *phidget22.DigitalInChannel {
+	return m.client.DigitalIn(channel, hubPort)
 }
 
 func (m *Manager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	url := fmt.Sprintf("http://%s%s/phidgets/state", r.Host, m.conf.Phidgets.HTTPAddr)
-	http.Redirect(w, r, url, http.StatusFound)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Connected bool `json:"connected"`
+	}{Connected: m.connected.Load()})
 }
@@ -0,0 +1,117 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package thermostat
+
+import (
+	"burlo/v2/pkg/sysmon"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// vtMetrics holds the Prometheus collectors published by VirtThermostat.
+// Mode and State are enum gauges: one series per known value, 1 for the
+// currently active value and 0 for the rest, the usual Prometheus pattern
+// for rendering a small enum as a graphable series.
+type vtMetrics struct {
+	temperature    prometheus.Gauge
+	setpoint       prometheus.Gauge
+	humidity       prometheus.Gauge
+	mode           *prometheus.GaugeVec
+	state          *prometheus.GaugeVec
+	changeSetpoint prometheus.Counter
+	toggleMode     prometheus.Counter
+}
+
+func newVTMetrics() *vtMetrics {
+	return &vtMetrics{
+		temperature: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "burlo_thermostat_temperature_celsius",
+			Help: "Current measured temperature.",
+		}),
+		setpoint: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "burlo_thermostat_setpoint_celsius",
+			Help: "Current setpoint.",
+		}),
+		humidity: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "burlo_thermostat_humidity_percent",
+			Help: "Current measured relative humidity.",
+		}),
+		mode: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "burlo_thermostat_mode",
+			Help: "1 for the thermostat's current mode, 0 for every other mode.",
+		}, []string{"mode"}),
+		state: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "burlo_thermostat_state",
+			Help: "1 for the thermostat's current state, 0 for every other state.",
+		}, []string{"state"}),
+		changeSetpoint: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "burlo_thermostat_change_setpoint_total",
+			Help: "Total change_setpoint commands received from a client.",
+		}),
+		toggleMode: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "burlo_thermostat_toggle_mode_total",
+			Help: "Total toggle_mode commands received from a client.",
+		}),
+	}
+}
+
+func (m *vtMetrics) register(reg *sysmon.Registry) {
+	if reg == nil {
+		return
+	}
+	reg.MustRegister(m.temperature, m.setpoint, m.humidity, m.mode, m.state, m.changeSetpoint, m.toggleMode)
+}
+
+// observe updates every gauge from the current data snapshot. Called once
+// per Run loop iteration, same as the WebAppState/ThermostatUpdate builds.
+func (m *vtMetrics) observe(d vtData) {
+	m.temperature.Set(d.TemperatureC)
+	m.setpoint.Set(d.SetpointC)
+	m.humidity.Set(d.Humidity)
+
+	for _, mode := range modeNames {
+		v := 0.0
+		if d.Mode == mode.value {
+			v = 1
+		}
+		m.mode.WithLabelValues(mode.name).Set(v)
+	}
+	for _, state := range stateNames {
+		v := 0.0
+		if d.State == state.value {
+			v = 1
+		}
+		m.state.WithLabelValues(state.name).Set(v)
+	}
+}
+
+var modeNames = []struct {
+	value VTMode
+	name  string
+}{
+	{Mode_OFF, "off"},
+	{Mode_HEAT, "heat"},
+	{Mode_ECO, "eco"},
+	{Mode_BOOST, "boost"},
+}
+
+var stateNames = []struct {
+	value VTState
+	name  string
+}{
+	{State_IDLE, "idle"},
+	{State_ACTIVE, "active"},
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package thermostat
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"burlo/v2/internal/events"
+	"burlo/v2/pkg/rpc"
+)
+
+// RPCServer adapts VirtThermostat onto rpc.ThermostatServer: GetState and
+// Subscribe read the same events.ThermostatUpdate already published to
+// the eventbus by Run, so a remote caller sees exactly what the web app
+// does; SetSetpoint/SetMode enqueue onto the same clientQueue the
+// websocket handler uses, so gRPC callers can't race or bypass it.
+type RPCServer struct {
+	vt *VirtThermostat
+}
+
+// NewRPCServer wraps vt for serving over gRPC.
+func NewRPCServer(vt *VirtThermostat) *RPCServer {
+	return &RPCServer{vt: vt}
+}
+
+func toRPCState(u events.ThermostatUpdate) *rpc.ThermostatState {
+	return &rpc.ThermostatState{
+		TemperatureC:          u.TemperatureC,
+		SetpointC:             u.SetpointC,
+		Humidity:              u.Humidity,
+		Mode:                  u.Mode,
+		State:                 u.State,
+		Battery:               u.Battery,
+		BatteryLow:            u.BatteryLow,
+		Valve:                 u.Valve,
+		EcoSetpointC:          u.EcoSetpointC,
+		BoostRemainingSeconds: u.BoostRemainingSeconds,
+		ZWaveOnline:           u.ZWaveOnline,
+		Timestamp:             time.Now(),
+	}
+}
+
+func (s *RPCServer) GetState(ctx context.Context, _ *rpc.Empty) (*rpc.ThermostatState, error) {
+	if s.vt.evBus == nil {
+		return nil, fmt.Errorf("no eventbus configured")
+	}
+	ev, ok := s.vt.evBus.GetLast(events.TopicThermostat)
+	if !ok {
+		return nil, fmt.Errorf("no thermostat state published yet")
+	}
+	update, ok := ev.(events.ThermostatUpdate)
+	if !ok {
+		return nil, fmt.Errorf("unexpected event type %T on %s", ev, events.TopicThermostat)
+	}
+	return toRPCState(update), nil
+}
+
+func (s *RPCServer) SetSetpoint(ctx context.Context, req *rpc.SetSetpointRequest) (*rpc.Empty, error) {
+	select {
+	case s.vt.clientQueue <- WebAppRequest{Command: "change_setpoint", DeltaC: req.DeltaC}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &rpc.Empty{}, nil
+}
+
+func (s *RPCServer) SetMode(ctx context.Context, _ *rpc.SetModeRequest) (*rpc.Empty, error) {
+	select {
+	case s.vt.clientQueue <- WebAppRequest{Command: "toggle_mode"}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &rpc.Empty{}, nil
+}
+
+func (s *RPCServer) Subscribe(_ *rpc.Empty, stream rpc.ThermostatService_SubscribeServer) error {
+	if s.vt.evBus == nil {
+		return fmt.Errorf("no eventbus configured")
+	}
+	ctx := stream.Context()
+	updates, unsubscribe := s.vt.evBus.Subscribe(ctx, events.TopicThermostat, true)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			update, ok := ev.(events.ThermostatUpdate)
+			if !ok {
+				continue
+			}
+			if err := stream.Send(toRPCState(update)); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -17,10 +17,14 @@ package thermostat
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"sync/atomic"
+	"time"
 
 	"burlo/v2/internal/config"
 	"burlo/v2/internal/events"
+	"burlo/v2/pkg/authz"
 	"burlo/v2/pkg/eventbus"
 	"burlo/v2/pkg/logger"
 	"burlo/v2/pkg/service"
@@ -29,21 +33,68 @@ import (
 type VirtThermostat struct {
 	conf        config.ThermostatConfig
 	evBus       *eventbus.Bus
+	authz       *authz.Issuer
 	clientQueue chan WebAppRequest
 
 	backend Backend
 	data    vtData
 	log     *logger.Logger
+	metrics *vtMetrics
 
+	// zwaveOnline mirrors vtData.ZWaveOnline for Health, which is called
+	// from an HTTP handler goroutine rather than Run's.
+	zwaveOnline atomic.Bool
+
+	rootDir     string
 	httpHandler http.Handler
+
+	// wsClients tracks connected websocket clients and assigns each
+	// broadcast its monotonic sequence number; broadcastCh feeds it from
+	// Run without ever blocking the main loop on a slow client.
+	wsClients   *wsClientSync
+	broadcastCh chan WebAppState
+
+	// AuthHook authorizes an inbound websocket connection before any
+	// mutating command from it reaches clientQueue; it returns a non-nil
+	// error to reject. The default, installed by NewZWaveThermostat, checks
+	// a bearer token via authz.FromRequest. Replacing it (e.g. with a
+	// session-cookie check) lets this same handler serve clients that don't
+	// carry a bearer token, without touching serveWebSockets itself.
+	AuthHook func(r *http.Request) error
+}
+
+// defaultAuthHook is the AuthHook installed by NewZWaveThermostat.
+func (vt *VirtThermostat) defaultAuthHook(r *http.Request) error {
+	_, err := vt.authz.Verify(authz.FromRequest(r), "thermostat")
+	return err
+}
+
+// Health reports whether the thermostat's Z-Wave connection is up, for
+// rootserv's /healthz aggregator.
+func (vt *VirtThermostat) Health() error {
+	if !vt.zwaveOnline.Load() {
+		return fmt.Errorf("zwave disconnected")
+	}
+	return nil
 }
 
 type vtData struct {
-	TemperatureC float64
-	SetpointC    float64
-	Humidity     float64
-	Mode         VTMode
-	State        VTState
+	TemperatureC   float64
+	SetpointC      float64
+	Humidity       float64
+	Mode           VTMode
+	State          VTState
+	Battery        float64
+	BatteryLow     bool
+	Valve          float64
+	EcoSetpointC   float64
+	BoostRemaining time.Duration
+
+	// ZWaveOnline tracks whether the backend's Z-Wave connection is
+	// currently up, starting true (no device hooks up OnConnState until
+	// it's actually lost the connection, which matches the old no-op
+	// behavior for backends that don't implement it).
+	ZWaveOnline bool
 }
 
 type VTMode int
@@ -53,6 +104,12 @@ const (
 	Mode_OFF          VTMode = 0
 	Mode_HEAT         VTMode = 1
 	Mode_SETBACK_HEAT VTMode = 11
+	Mode_ECO          VTMode = Mode_SETBACK_HEAT
+
+	// Mode_BOOST is a synthetic, application-level mode: the device itself
+	// stays in Mode_HEAT with its setpoint temporarily overridden, so this
+	// value is never sent through Backend.SetMode.
+	Mode_BOOST VTMode = 99
 )
 
 const (
@@ -70,6 +127,10 @@ type Backend interface {
 	service.Runnable
 	SetMode(mode VTMode) error
 	SetSetpoint(c float64) error
+	SetEcoSetpoint(c float64) error
+	GetEcoSetpoint() (float64, error)
+	SetBoost(duration time.Duration) error
+	CancelBoost()
 	Updates() <-chan BackendUpdate
 }
 
@@ -80,10 +141,21 @@ func NewZWaveThermostat(conf *config.Config) *VirtThermostat {
 	vt := &VirtThermostat{
 		conf:        conf.Thermostat,
 		evBus:       conf.EventBus,
+		authz:       conf.Authz,
 		clientQueue: make(chan WebAppRequest, 8),
 		backend:     backend,
 		log:         log,
+		metrics:     newVTMetrics(),
+		rootDir:     conf.RootDir,
+		wsClients:   newWSClientSync(),
+		broadcastCh: make(chan WebAppState, 4),
 	}
+	vt.AuthHook = vt.defaultAuthHook
+	vt.metrics.register(conf.MetricsRegistry)
+	// assume online until the backend reports otherwise, so a backend that
+	// never calls OnConnState (or hasn't connected yet) doesn't show offline
+	vt.data.ZWaveOnline = true
+	vt.zwaveOnline.Store(true)
 	vt.httpHandler = vt.buildHTTPHandler()
 	return vt
 }
@@ -94,6 +166,8 @@ func (vt *VirtThermostat) Run(ctx context.Context) {
 	go vt.backend.Run(ctx)
 	backendUpdates := vt.backend.Updates()
 
+	go vt.runBroadcaster(ctx)
+
 	vt.clientQueue <- WebAppRequest{Command: "broadcast"}
 
 	for {
@@ -108,6 +182,8 @@ func (vt *VirtThermostat) Run(ctx context.Context) {
 				return
 			}
 
+			prevMode := vt.data.Mode
+
 			switch msg.Property {
 			case "temperature":
 				vt.data.TemperatureC = msg.Value.(float64)
@@ -119,6 +195,19 @@ func (vt *VirtThermostat) Run(ctx context.Context) {
 				vt.data.Mode = msg.Value.(VTMode)
 			case "state":
 				vt.data.State = msg.Value.(VTState)
+			case "battery":
+				vt.data.Battery = msg.Value.(float64)
+			case "battery_low":
+				vt.data.BatteryLow = msg.Value.(bool)
+			case "valve":
+				vt.data.Valve = msg.Value.(float64)
+			case "eco_setpoint":
+				vt.data.EcoSetpointC = msg.Value.(float64)
+			case "boost_remaining":
+				vt.data.BoostRemaining = msg.Value.(time.Duration)
+			case "zwave_online":
+				vt.data.ZWaveOnline = msg.Value.(bool)
+				vt.zwaveOnline.Store(vt.data.ZWaveOnline)
 			case "none":
 				// ignore
 			default:
@@ -126,6 +215,8 @@ func (vt *VirtThermostat) Run(ctx context.Context) {
 				continue
 			}
 
+			vt.publishModeTransition(prevMode, vt.data.Mode)
+
 			if !msg.Broadcast {
 				continue
 			}
@@ -136,42 +227,121 @@ func (vt *VirtThermostat) Run(ctx context.Context) {
 			case "broadcast":
 				// just forward
 			case "change_setpoint":
+				vt.metrics.changeSetpoint.Inc()
 				vt.data.SetpointC = vt.deltaSetpoint(req.DeltaC)
 				if err := vt.backend.SetSetpoint(vt.data.SetpointC); err != nil {
 					vt.log.Error("SetSetpoint failed: %v", err)
 				}
 			case "toggle_mode":
+				vt.metrics.toggleMode.Inc()
+				prevMode := vt.data.Mode
 				vt.data.Mode = vt.data.Mode.toggle()
 				if err := vt.backend.SetMode(vt.data.Mode); err != nil {
 					vt.log.Error("SetMode failed: %v", err)
 				}
+				vt.publishModeTransition(prevMode, vt.data.Mode)
+			case "set_eco_setpoint":
+				if err := vt.backend.SetEcoSetpoint(req.Value); err != nil {
+					vt.log.Error("SetEcoSetpoint failed: %v", err)
+				}
+			case "boost":
+				duration := time.Duration(req.Value) * time.Second
+				if err := vt.backend.SetBoost(duration); err != nil {
+					vt.log.Error("SetBoost failed: %v", err)
+				}
+			case "cancel_boost":
+				vt.backend.CancelBoost()
+			case "set_mode":
+				prevMode := vt.data.Mode
+				vt.data.Mode = VTMode(int(req.Mode))
+				if err := vt.backend.SetMode(vt.data.Mode); err != nil {
+					vt.log.Error("SetMode failed: %v", err)
+				}
+				vt.publishModeTransition(prevMode, vt.data.Mode)
+			case "set_schedule":
+				// No schedule subsystem exists in this tree yet; accept the
+				// message so clients get a response instead of a dropped
+				// connection, but don't pretend to apply it.
+				vt.log.Info("set_schedule received but not yet implemented; ignoring")
 			default:
 				continue
 			}
 		}
 
+		vt.metrics.observe(vt.data)
+
 		state := WebAppState{
-			TemperatureC: vt.data.TemperatureC,
-			SetpointC:    vt.data.SetpointC,
-			Humidity:     vt.data.Humidity,
-			Mode:         int(vt.data.Mode),
-			State:        int(vt.data.State),
+			TemperatureC:          vt.data.TemperatureC,
+			SetpointC:             vt.data.SetpointC,
+			Humidity:              vt.data.Humidity,
+			Mode:                  int(vt.data.Mode),
+			State:                 int(vt.data.State),
+			Battery:               vt.data.Battery,
+			BatteryLow:            vt.data.BatteryLow,
+			Valve:                 vt.data.Valve,
+			EcoSetpointC:          vt.data.EcoSetpointC,
+			BoostRemainingSeconds: vt.data.BoostRemaining.Seconds(),
+			ZWaveOnline:           vt.data.ZWaveOnline,
 		}
 
-		go webAppBroadcast(state)
+		select {
+		case vt.broadcastCh <- state:
+		default:
+			vt.log.Debug("broadcast channel full; dropping state update")
+		}
 
 		if vt.evBus != nil {
 			vt.evBus.Publish(events.TopicThermostat, events.ThermostatUpdate{
-				TemperatureC: vt.data.TemperatureC,
-				SetpointC:    vt.data.SetpointC,
-				Humidity:     vt.data.Humidity,
-				Mode:         int(vt.data.Mode),
-				State:        int(vt.data.State),
+				TemperatureC:          vt.data.TemperatureC,
+				SetpointC:             vt.data.SetpointC,
+				Humidity:              vt.data.Humidity,
+				Mode:                  int(vt.data.Mode),
+				State:                 int(vt.data.State),
+				Battery:               vt.data.Battery,
+				BatteryLow:            vt.data.BatteryLow,
+				Valve:                 vt.data.Valve,
+				EcoSetpointC:          vt.data.EcoSetpointC,
+				BoostRemainingSeconds: vt.data.BoostRemaining.Seconds(),
+				ZWaveOnline:           vt.data.ZWaveOnline,
 			})
 		}
 	}
 }
 
+// runBroadcaster is the sole consumer of broadcastCh, so it assigns each
+// websocket broadcast's sequence number in the same order Run generated
+// the states, even though Run itself never blocks waiting for slow
+// clients to drain.
+func (vt *VirtThermostat) runBroadcaster(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case state := <-vt.broadcastCh:
+			vt.wsClients.broadcast(state, vt.log)
+		}
+	}
+}
+
+// publishModeTransition emits mode_eco/mode_boost events when the
+// thermostat enters or leaves those modes, so schedulers don't need to
+// poll ThermostatUpdate to notice.
+func (vt *VirtThermostat) publishModeTransition(prev, current VTMode) {
+	if vt.evBus == nil || prev == current {
+		return
+	}
+	if current == Mode_ECO {
+		vt.evBus.Publish(events.TopicModeEco, events.ModeEcoEvent{Active: true})
+	} else if prev == Mode_ECO {
+		vt.evBus.Publish(events.TopicModeEco, events.ModeEcoEvent{Active: false})
+	}
+	if current == Mode_BOOST {
+		vt.evBus.Publish(events.TopicModeBoost, events.ModeBoostEvent{Active: true})
+	} else if prev == Mode_BOOST {
+		vt.evBus.Publish(events.TopicModeBoost, events.ModeBoostEvent{Active: false})
+	}
+}
+
 func (vt *VirtThermostat) deltaSetpoint(delta float64) float64 {
 	newSetpoint := vt.data.SetpointC + delta
 	if newSetpoint > vt.conf.MaxSetpointC {
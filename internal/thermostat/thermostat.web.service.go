@@ -16,67 +16,109 @@
 package thermostat
 
 import (
-	"burlo/v2/pkg/logger"
 	"encoding/json"
 	"net/http"
 	"path/filepath"
 	"strings"
 	"sync"
 
+	"burlo/v2/internal/events"
+	"burlo/v2/pkg/logger"
+
 	"github.com/gorilla/websocket"
 )
 
+// WebAppRequest is one inbound websocket (or future REST) message from the
+// web app. Command selects which fields apply: "change_setpoint" uses
+// DeltaC, "set_mode" uses Mode, "set_eco_setpoint"/"boost" use Value, and
+// "set_schedule" uses Schedule.
 type WebAppRequest struct {
 	Command string  `json:"command"`
 	DeltaC  float64 `json:"delta,omitempty"`
 	Mode    float64 `json:"mode,omitempty"`
+	// Value carries the eco setpoint (degrees C) for "set_eco_setpoint" and
+	// the requested duration (seconds) for "boost".
+	Value float64 `json:"value,omitempty"`
+	// Schedule carries a "set_schedule" payload. Its shape is not yet
+	// defined since this tree has no schedule subsystem; it's decoded as
+	// raw JSON so the message still round-trips instead of failing to parse.
+	Schedule json.RawMessage `json:"schedule,omitempty"`
 }
 
 type WebAppState struct {
-	TemperatureC float64 `json:"temperature"`
-	SetpointC    float64 `json:"setpoint"`
-	Humidity     float64 `json:"humidity"`
-	Mode         int     `json:"mode"`
-	State        int     `json:"state"`
+	TemperatureC          float64 `json:"temperature"`
+	SetpointC             float64 `json:"setpoint"`
+	Humidity              float64 `json:"humidity"`
+	Mode                  int     `json:"mode"`
+	State                 int     `json:"state"`
+	Battery               float64 `json:"battery"`
+	BatteryLow            bool    `json:"battery_low"`
+	Valve                 float64 `json:"valve"`
+	EcoSetpointC          float64 `json:"eco_setpoint"`
+	BoostRemainingSeconds float64 `json:"boost_remaining_seconds"`
+	ZWaveOnline           bool    `json:"zwave_online"`
 }
 
-type ClientSync struct {
-	clients map[*websocket.Conn]bool
-	mutex   sync.Mutex
+// WebAppStateMsg is the JSON envelope pushed to every connected websocket
+// client: WebAppState plus a monotonic Seq, so a client that notices a gap
+// (e.g. after a reconnect) knows it missed updates instead of silently
+// treating stale state as current.
+type WebAppStateMsg struct {
+	Seq uint64 `json:"seq"`
+	WebAppState
 }
 
-var clients = ClientSync{clients: make(map[*websocket.Conn]bool)}
+// wsClientSync tracks the websocket connections for one VirtThermostat and
+// assigns each broadcast the next sequence number. Unlike the package-level
+// client registry this replaces, it's owned by a single VirtThermostat so
+// tests or a second instance don't share state.
+type wsClientSync struct {
+	mu      sync.Mutex
+	clients map[*websocket.Conn]bool
+	seq     uint64
+}
 
-func (c *ClientSync) broadcast(pm *websocket.PreparedMessage, log *logger.Logger) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	for ws := range c.clients {
-		if err := ws.WritePreparedMessage(pm); err != nil {
-			log.Error("failed to write message: %v", err)
-			ws.Close()
-			delete(c.clients, ws)
-		}
-	}
+func newWSClientSync() *wsClientSync {
+	return &wsClientSync{clients: make(map[*websocket.Conn]bool)}
 }
 
-func (c *ClientSync) add(ws *websocket.Conn) {
-	c.mutex.Lock()
+func (c *wsClientSync) add(ws *websocket.Conn) {
+	c.mu.Lock()
 	c.clients[ws] = true
-	c.mutex.Unlock()
+	c.mu.Unlock()
 }
 
-func (c *ClientSync) remove(ws *websocket.Conn) {
-	c.mutex.Lock()
+func (c *wsClientSync) remove(ws *websocket.Conn) {
+	c.mu.Lock()
 	delete(c.clients, ws)
-	c.mutex.Unlock()
+	c.mu.Unlock()
 }
 
-func (c *ClientSync) closeAll() {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+// broadcast assigns state the next sequence number and sends it to every
+// connected client, dropping any whose write fails. The whole call runs
+// under one lock so sequence numbers are assigned and delivered in the
+// same order across all clients.
+func (c *wsClientSync) broadcast(state WebAppState, log *logger.Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	data, err := json.Marshal(WebAppStateMsg{Seq: c.seq, WebAppState: state})
+	if err != nil {
+		log.Error("failed to marshal broadcast: %v", err)
+		return
+	}
+	pm, err := websocket.NewPreparedMessage(websocket.TextMessage, data)
+	if err != nil {
+		log.Error("failed to prepare message: %v", err)
+		return
+	}
 	for ws := range c.clients {
-		ws.Close()
-		delete(c.clients, ws)
+		if err := ws.WritePreparedMessage(pm); err != nil {
+			log.Error("failed to write message: %v", err)
+			ws.Close()
+			delete(c.clients, ws)
+		}
 	}
 }
 
@@ -85,6 +127,7 @@ func (vt *VirtThermostat) buildHTTPHandler() http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", vt.serveRoot(assetsDir))
 	mux.HandleFunc("/ws", vt.serveWebSockets(vt.clientQueue))
+	mux.HandleFunc("/api/v1/state", vt.serveState)
 	return mux
 }
 
@@ -104,6 +147,46 @@ func (vt *VirtThermostat) serveRoot(assetsDir string) http.HandlerFunc {
 	}
 }
 
+// serveState is a REST fallback for clients that would rather poll than
+// hold a websocket open. It reads the same last-published
+// events.ThermostatUpdate the gRPC GetState RPC and websocket broadcasts
+// are derived from, so all three surfaces agree.
+func (vt *VirtThermostat) serveState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if vt.evBus == nil {
+		http.Error(w, "no state published yet", http.StatusServiceUnavailable)
+		return
+	}
+	ev, ok := vt.evBus.GetLast(events.TopicThermostat)
+	if !ok {
+		http.Error(w, "no state published yet", http.StatusServiceUnavailable)
+		return
+	}
+	update, ok := ev.(events.ThermostatUpdate)
+	if !ok {
+		http.Error(w, "unexpected event type", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(WebAppState{
+		TemperatureC:          update.TemperatureC,
+		SetpointC:             update.SetpointC,
+		Humidity:              update.Humidity,
+		Mode:                  update.Mode,
+		State:                 update.State,
+		Battery:               update.Battery,
+		BatteryLow:            update.BatteryLow,
+		Valve:                 update.Valve,
+		EcoSetpointC:          update.EcoSetpointC,
+		BoostRemainingSeconds: update.BoostRemainingSeconds,
+		ZWaveOnline:           update.ZWaveOnline,
+	})
+}
+
 func (vt *VirtThermostat) serveWebSockets(msgQueue chan WebAppRequest) http.HandlerFunc {
 	upgrader := websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
@@ -120,14 +203,21 @@ func (vt *VirtThermostat) serveWebSockets(msgQueue chan WebAppRequest) http.Hand
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		if err := vt.AuthHook(r); err != nil {
+			vt.log.Debug("rejecting websocket: %v", err)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
 		ws, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			vt.log.Error("failed to upgrade websocket: %v", err)
 			return
 		}
-		clients.add(ws)
+		wsLog := vt.log.With("remoteAddr", r.RemoteAddr)
+		vt.wsClients.add(ws)
 		defer func() {
-			clients.remove(ws)
+			vt.wsClients.remove(ws)
 			ws.Close()
 		}()
 
@@ -142,29 +232,14 @@ func (vt *VirtThermostat) serveWebSockets(msgQueue chan WebAppRequest) http.Hand
 				if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
 					break
 				}
-				vt.log.Error("failed ws ReadJSON: %v", err)
+				wsLog.Error("failed ws ReadJSON: %v", err)
 				break
 			}
 			select {
 			case msgQueue <- req:
 			default:
-				vt.log.Debug("clientQueue is full; dropping client message")
+				wsLog.Debug("clientQueue is full; dropping client message")
 			}
 		}
 	}
 }
-
-func webAppBroadcast(msg WebAppState) {
-	log := logger.New("ThermostatWeb")
-	data, err := json.Marshal(msg)
-	if err != nil {
-		log.Error("failed to marshal broadcast: %v", err)
-		return
-	}
-	pm, err := websocket.NewPreparedMessage(websocket.TextMessage, data)
-	if err != nil {
-		log.Error("failed to prepare message: %v", err)
-		return
-	}
-	clients.broadcast(pm, log)
-}
@@ -21,13 +21,23 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"burlo/v2/internal/config"
+	"burlo/v2/internal/events"
+	"burlo/v2/pkg/eventbus"
 	"burlo/v2/pkg/logger"
 	"burlo/v2/pkg/zwavejsws"
 )
 
+// ZWaveClient is the zwave-js client backing the thermostat, exposed so
+// other subsystems that want to write to the same Z-Wave network (e.g.
+// mqttbridge relaying inbound MQTT commands) can share it rather than
+// opening a second connection, mirroring dx2w.ModbusClient's role for the
+// DX2W Modbus connection.
+var ZWaveClient *zwavejsws.Client
+
 type zWaveThermostatBackend struct {
 	zwaveclient *zwavejsws.Client
 	updates     chan BackendUpdate
@@ -35,21 +45,55 @@ type zWaveThermostatBackend struct {
 	log         *logger.Logger
 	nodeId      int
 	units       Unit
+	evBus       *eventbus.Bus
+
+	// batteryLowActive tracks the last reported battery_low state so the
+	// event only fires once per transition rather than on every battery
+	// report while it remains low.
+	batteryLowActive bool
+
+	stateMu      sync.Mutex
+	lastMode     VTMode
+	lastSetpoint float64
+	ecoSetpoint  float64
+
+	boostMu      sync.Mutex
+	boostActive  bool
+	boostCancel  context.CancelFunc
+	preBoostMode VTMode
+	preBoostSP   float64
 }
 
 func NewZWaveBackend(conf *config.Config, log *logger.Logger) Backend {
+	var zwaveclient *zwavejsws.Client
+	if conf.Thermostat.ZWaveMode == "remote" {
+		zwaveclient = zwavejsws.NewRemoteClient(conf.Thermostat.ZWaveAgentAddr)
+	} else {
+		zwaveclient = zwavejsws.NewClient(conf.Thermostat.ZWaveAddr, conf.Thermostat.ZWaveToken)
+	}
+
 	backend := &zWaveThermostatBackend{
 		log:         log,
 		conf:        conf.Thermostat,
 		updates:     make(chan BackendUpdate, 8),
 		nodeId:      conf.Thermostat.ZWaveDeviceId,
-		zwaveclient: zwavejsws.NewClient(conf.Thermostat.ZWaveAddr),
+		zwaveclient: zwaveclient,
+		evBus:       conf.EventBus,
 	}
 	backend.zwaveclient.OnState(backend.identifyAndInitZWaveThermostat)
 	backend.zwaveclient.OnEvent(backend.handleThermostatNodeEvents)
+	backend.zwaveclient.OnConnState(backend.handleZWaveConnState)
+	ZWaveClient = zwaveclient
 	return backend
 }
 
+// handleZWaveConnState surfaces the backend's Z-Wave connectivity as a
+// broadcast update, so the /thermostat web UI and eventbus consumers can
+// show "zwave offline" instead of silently going stale.
+func (b *zWaveThermostatBackend) handleZWaveConnState(connected bool) {
+	b.updates <- BackendUpdate{Property: "zwave_online", Value: connected, Broadcast: true}
+}
+
 func (b *zWaveThermostatBackend) Updates() <-chan BackendUpdate {
 	return b.updates
 }
@@ -102,6 +146,8 @@ func (b *zWaveThermostatBackend) identifyAndInitZWaveThermostat(state zwavejsws.
 		tnode = thermostatNodes[0]
 	}
 
+	b.log = b.log.With("nodeId", b.nodeId)
+
 	b.log.Info("found %d thermostat node(s)", len(thermostatNodes))
 	b.log.Info("using thermostat nodeId %d", tnode.NodeID)
 	b.log.Info("zwave node [name=%s, location=%s]", tnode.Name, tnode.Location)
@@ -131,9 +177,20 @@ func (b *zWaveThermostatBackend) identifyAndInitZWaveThermostat(state zwavejsws.
 		if val.CommandClass == 67 && val.PropertyName == "setpoint" && val.Metadata.CCSpecific.SetpointType == 1 {
 			b.log.Info("Current setpoint: %v %v", val.Value.(float64), val.Metadata.Unit)
 			b.units = fromZwaveThermostatUnits(val.Metadata.Unit)
+			setpoint := fromZwaveThermostatTemp(val.Value.(float64), b.units)
+			b.setLastSetpoint(setpoint)
 			b.updates <- BackendUpdate{
 				Property: "setpoint",
-				Value:    fromZwaveThermostatTemp(val.Value.(float64), b.units),
+				Value:    setpoint,
+			}
+		}
+		if val.CommandClass == 67 && val.PropertyName == "setpoint" && val.Metadata.CCSpecific.SetpointType == 11 {
+			b.log.Info("Current eco setpoint: %v %v", val.Value.(float64), val.Metadata.Unit)
+			ecoSetpoint := fromZwaveThermostatTemp(val.Value.(float64), b.units)
+			b.setEcoSetpointCache(ecoSetpoint)
+			b.updates <- BackendUpdate{
+				Property: "eco_setpoint",
+				Value:    ecoSetpoint,
 			}
 		}
 		if val.CommandClass == 66 && val.PropertyName == "state" {
@@ -147,9 +204,26 @@ func (b *zWaveThermostatBackend) identifyAndInitZWaveThermostat(state zwavejsws.
 		if val.CommandClass == 64 && val.PropertyName == "mode" {
 			mode := int(val.Value.(float64))
 			b.log.Info("Current mode: %+v", mode)
+			vtMode := fromZwaveThermostatMode(mode)
+			b.setLastMode(vtMode)
 			b.updates <- BackendUpdate{
 				Property: "mode",
-				Value:    fromZwaveThermostatMode(mode),
+				Value:    vtMode,
+			}
+		}
+		if val.CommandClass == 128 && val.PropertyName == "level" {
+			level := val.Value.(float64)
+			b.log.Info("Current battery level: %v%%", level)
+			b.batteryLowActive = level < b.conf.BatteryLowPercent
+			b.updates <- BackendUpdate{Property: "battery", Value: level}
+			b.updates <- BackendUpdate{Property: "battery_low", Value: b.batteryLowActive}
+		}
+		if val.CommandClass == 38 && val.PropertyName == "currentValue" {
+			pos := val.Value.(float64)
+			b.log.Info("Current valve position: %v%%", pos)
+			b.updates <- BackendUpdate{
+				Property: "valve",
+				Value:    pos,
 			}
 		}
 	}
@@ -161,6 +235,8 @@ func (b *zWaveThermostatBackend) identifyAndInitZWaveThermostat(state zwavejsws.
 
 // handleThermostatNodeEvents filters events for our thermostat node
 func (b *zWaveThermostatBackend) handleThermostatNodeEvents(event zwavejsws.Event) {
+	b.publishRawZWaveEvent(event)
+
 	if event.NodeID != b.nodeId {
 		return
 	}
@@ -181,6 +257,27 @@ func (b *zWaveThermostatBackend) handleThermostatNodeEvents(event zwavejsws.Even
 	}
 }
 
+// publishRawZWaveEvent republishes every node's value updates onto the
+// eventbus, unfiltered by b.nodeId, so subsystems that care about the
+// whole Z-Wave network (e.g. mqttbridge) don't need their own OnEvent
+// registration racing this backend's.
+func (b *zWaveThermostatBackend) publishRawZWaveEvent(event zwavejsws.Event) {
+	if b.evBus == nil || !event.IsValueUpdate() {
+		return
+	}
+	val, err := event.ParseValueUpdated()
+	if err != nil {
+		return
+	}
+	b.evBus.Publish(events.TopicZWaveRaw, events.ZWaveRawUpdate{
+		NodeID:           event.NodeID,
+		CommandClassName: val.CommandClassName,
+		PropertyName:     val.PropertyName,
+		Value:            val.NewValue,
+		Timestamp:        time.Now(),
+	})
+}
+
 func (b *zWaveThermostatBackend) handleValueUpdate(event zwavejsws.Event) {
 	val, err := event.ParseValueUpdated()
 	if err != nil {
@@ -201,14 +298,16 @@ func (b *zWaveThermostatBackend) handleValueUpdate(event zwavejsws.Event) {
 	case 64: // Thermostat Mode
 		b.handleThermostatMode(&val)
 
+	case 128: // Battery
+		b.handleBattery(&val)
+
+	case 38: // Multilevel Switch (valve position, TRV-style devices e.g. Eurotronic Spirit)
+		b.handleMultilevelSwitch(&val)
+
 	default:
 		b.log.Error("zwave-js unhandled value update: %+v", val)
 		b.log.Debug("zwave-js unhandled value: \n%+v\n", val)
 		return
-
-		// TODO: handle battery update event:
-		// 2025/10/10 03:15:13 [Thermostat] ERROR: (thermostat.zwave.backend.go:190)
-		// zwave-js unhandled value update: {CommandClass:128 CommandClassName:Battery Endpoint:0 NewValue:95 PrevValue:100 Property:level PropertyName:level PropertyKey:<nil> PropertyKeyName:}
 	}
 }
 
@@ -250,12 +349,15 @@ func (b *zWaveThermostatBackend) handleThermostatSetpoint(val *zwavejsws.Updated
 	}
 	switch propertyKey {
 	case 1: // heating
-		b.broadcastUpdate("setpoint",
-			fromZwaveThermostatTemp(val.NewValue.(float64), b.units))
+		setpoint := fromZwaveThermostatTemp(val.NewValue.(float64), b.units)
+		b.setLastSetpoint(setpoint)
+		b.broadcastUpdate("setpoint", setpoint)
 	case 0: // cooling?
 		// ignore
 	case 11: // away heating (Energy Save Heating)
-		// ignore
+		ecoSetpoint := fromZwaveThermostatTemp(val.NewValue.(float64), b.units)
+		b.setEcoSetpointCache(ecoSetpoint)
+		b.broadcastUpdate("eco_setpoint", ecoSetpoint)
 	default:
 		b.log.Error("zwave-js unhandled setpoint update (unknown propertyKey): \n%+v\n", val)
 	}
@@ -268,7 +370,77 @@ func (b *zWaveThermostatBackend) handleThermostatMode(val *zwavejsws.UpdatedValu
 		b.log.Error("failed to parse thermostat mode: %+v", val)
 		return
 	}
-	b.broadcastUpdate("mode", fromZwaveThermostatMode(modeNum))
+	mode := fromZwaveThermostatMode(modeNum)
+	b.setLastMode(mode)
+	b.broadcastUpdate("mode", mode)
+}
+
+// Handles CommandClass 128
+func (b *zWaveThermostatBackend) handleBattery(val *zwavejsws.UpdatedValue) {
+	switch val.Property {
+	case "level":
+		level, ok := parseNumberToFloat(val.NewValue)
+		if !ok {
+			b.log.Error("zwave-js unhandled battery level update: \n%+v\n", val)
+			return
+		}
+		b.broadcastUpdate("battery", level)
+		b.updateBatteryLow(level < b.conf.BatteryLowPercent)
+
+	case "isLow":
+		isLow, ok := val.NewValue.(bool)
+		if !ok {
+			b.log.Error("zwave-js unhandled battery isLow update: \n%+v\n", val)
+			return
+		}
+		b.updateBatteryLow(isLow)
+
+	case "chargingStatus":
+		// ignore: rechargeable TRVs report this, but nothing acts on it yet
+
+	default:
+		b.log.Debug("zwave-js unhandled battery property: \n%+v\n", val)
+	}
+}
+
+// updateBatteryLow only broadcasts battery_low on an actual state
+// transition, so a TRV reporting the same low level repeatedly doesn't
+// spam an alert every poll.
+func (b *zWaveThermostatBackend) updateBatteryLow(isLow bool) {
+	if isLow == b.batteryLowActive {
+		return
+	}
+	b.batteryLowActive = isLow
+	b.broadcastUpdate("battery_low", isLow)
+}
+
+// Handles CommandClass 38 (valve position on TRV-style devices, e.g. the
+// Eurotronic Spirit, reported as a 0-100 Multilevel Switch value)
+func (b *zWaveThermostatBackend) handleMultilevelSwitch(val *zwavejsws.UpdatedValue) {
+	if val.Property != "currentValue" {
+		return
+	}
+	pos, ok := parseNumberToFloat(val.NewValue)
+	if !ok {
+		b.log.Error("zwave-js unhandled valve position update: \n%+v\n", val)
+		return
+	}
+	b.broadcastUpdate("valve", pos)
+}
+
+func parseNumberToFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	default:
+		return 0, false
+	}
 }
 
 func parseNumberToInt(value any) (int, bool) {
@@ -343,6 +515,7 @@ func (b *zWaveThermostatBackend) SetMode(m VTMode) error {
 	if err != nil {
 		return fmt.Errorf("failed to send mode: %v", err)
 	}
+	b.setLastMode(m)
 	return nil
 }
 
@@ -363,33 +536,161 @@ func (b *zWaveThermostatBackend) SetSetpoint(sp float64) error {
 	if err != nil {
 		return fmt.Errorf("failed to send setpoint: %v", err)
 	}
+	b.setLastSetpoint(sp)
 	return nil
 }
 
-func (b *zWaveThermostatBackend) Run(ctx context.Context) {
-	b.log.Info("starting Z-Wave backend")
-	defer b.log.Info("stopping Z-Wave backend")
-	defer close(b.updates)
+func (b *zWaveThermostatBackend) setLastMode(m VTMode) {
+	b.stateMu.Lock()
+	b.lastMode = m
+	b.stateMu.Unlock()
+}
+
+func (b *zWaveThermostatBackend) setLastSetpoint(c float64) {
+	b.stateMu.Lock()
+	b.lastSetpoint = c
+	b.stateMu.Unlock()
+}
+
+func (b *zWaveThermostatBackend) setEcoSetpointCache(c float64) {
+	b.stateMu.Lock()
+	b.ecoSetpoint = c
+	b.stateMu.Unlock()
+}
+
+// SetEcoSetpoint pushes the Energy Save Heating setpoint (CC 67, propertyKey 11).
+func (b *zWaveThermostatBackend) SetEcoSetpoint(c float64) error {
+	zwtSetpoint := toZwaveThermostatTemp(c, b.units)
+	err := b.zwaveclient.SendCommand(map[string]any{
+		"messageId": fmt.Sprintf("set:eco_setpoint(%.1f)[%d]", zwtSetpoint, time.Now().UnixNano()),
+		"command":   "node.set_value",
+		"nodeId":    b.nodeId,
+		"value":     zwtSetpoint,
+		"valueId": map[string]any{
+			"commandClass": 67,
+			"property":     "setpoint",
+			"propertyKey":  11, // Energy Save Heating
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send eco setpoint: %v", err)
+	}
+	b.setEcoSetpointCache(c)
+	return nil
+}
+
+// GetEcoSetpoint returns the last known Energy Save Heating setpoint.
+func (b *zWaveThermostatBackend) GetEcoSetpoint() (float64, error) {
+	b.stateMu.Lock()
+	defer b.stateMu.Unlock()
+	return b.ecoSetpoint, nil
+}
+
+// SetBoost records the current mode/setpoint, drives the heating setpoint
+// to conf.BoostSetpoint for duration (capped at conf.BoostMaxDurationSeconds),
+// and restores the prior mode/setpoint on expiry or cancellation. Progress
+// is reported via "boost_remaining" updates once per second.
+func (b *zWaveThermostatBackend) SetBoost(duration time.Duration) error {
+	if max := time.Duration(b.conf.BoostMaxDurationSeconds) * time.Second; max > 0 && duration > max {
+		duration = max
+	}
+	if duration <= 0 {
+		return fmt.Errorf("boost duration must be positive")
+	}
+
+	b.boostMu.Lock()
+	if b.boostCancel != nil {
+		b.boostCancel() // cancel any boost already in progress before starting a new one
+	}
+	if !b.boostActive {
+		// Only capture pre-boost state from lastMode/lastSetpoint when
+		// there isn't one already: by the time a second boost supersedes
+		// the first, those fields hold the first boost's own SetMode/
+		// SetSetpoint values, not the real state from before any boost.
+		b.stateMu.Lock()
+		b.preBoostMode = b.lastMode
+		b.preBoostSP = b.lastSetpoint
+		b.stateMu.Unlock()
+	}
+	b.boostActive = true
+	ctx, cancel := context.WithCancel(context.Background())
+	b.boostCancel = cancel
+	b.boostMu.Unlock()
+
+	if err := b.SetMode(Mode_HEAT); err != nil {
+		return fmt.Errorf("boost: failed to set heat mode: %v", err)
+	}
+	if err := b.SetSetpoint(b.conf.BoostSetpoint); err != nil {
+		return fmt.Errorf("boost: failed to set boost setpoint: %v", err)
+	}
+	b.broadcastUpdate("mode", Mode_BOOST)
+
+	go b.runBoostTimer(ctx, duration)
+	return nil
+}
+
+func (b *zWaveThermostatBackend) runBoostTimer(ctx context.Context, duration time.Duration) {
+	deadline := time.Now().Add(duration)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		default:
-			err := b.zwaveclient.Connect(ctx)
-			if err != nil {
-				b.log.Info("failed to connect to zwaveclient: %v", err)
-				time.Sleep(5 * time.Second)
-				continue
-			}
-			err = b.zwaveclient.ListenNext()
-			if err != nil {
-				b.zwaveclient.Close()
+		case now := <-ticker.C:
+			remaining := deadline.Sub(now)
+			if remaining <= 0 {
+				b.endBoost()
+				return
 			}
+			b.updates <- BackendUpdate{Property: "boost_remaining", Value: remaining, Broadcast: true}
 		}
 	}
 }
 
+// endBoost restores the mode/setpoint recorded before the boost began.
+func (b *zWaveThermostatBackend) endBoost() {
+	b.boostMu.Lock()
+	if !b.boostActive {
+		b.boostMu.Unlock()
+		return
+	}
+	b.boostActive = false
+	prevMode, prevSetpoint := b.preBoostMode, b.preBoostSP
+	b.boostMu.Unlock()
+
+	if err := b.SetMode(prevMode); err != nil {
+		b.log.Error("boost: failed to restore mode: %v", err)
+	}
+	if err := b.SetSetpoint(prevSetpoint); err != nil {
+		b.log.Error("boost: failed to restore setpoint: %v", err)
+	}
+	b.updates <- BackendUpdate{Property: "boost_remaining", Value: time.Duration(0), Broadcast: true}
+}
+
+// CancelBoost ends an active boost early, restoring the prior mode/setpoint
+// immediately instead of waiting for the timer to expire.
+func (b *zWaveThermostatBackend) CancelBoost() {
+	b.boostMu.Lock()
+	active := b.boostActive
+	cancel := b.boostCancel
+	b.boostMu.Unlock()
+	if !active {
+		return
+	}
+	cancel()
+	b.endBoost()
+}
+
+func (b *zWaveThermostatBackend) Run(ctx context.Context) {
+	b.log.Info("starting Z-Wave backend")
+	defer b.log.Info("stopping Z-Wave backend")
+	defer close(b.updates)
+
+	b.zwaveclient.Run(ctx)
+}
+
 type Unit int
 
 var Unit_Celsius Unit = 0
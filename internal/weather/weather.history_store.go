@@ -0,0 +1,143 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package weather
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const historyFilename = "weather_history.jsonl"
+
+// HistoryStore persists Entry samples so history survives a restart. The
+// only implementation today is fileHistoryStore; the interface exists so
+// an alternate backend (e.g. one of the datalogger.Sink destinations) can
+// stand in without touching Weather itself.
+type HistoryStore interface {
+	// Append adds one Entry, durably, before pollOnce returns.
+	Append(Entry) error
+	// Load returns every Entry at or after since, oldest first.
+	Load(since time.Time) ([]Entry, error)
+	// Compact drops every Entry older than since, so the backing file
+	// doesn't grow without bound for the life of the process.
+	Compact(since time.Time) error
+}
+
+// fileHistoryStore is a JSON-lines file: one Entry per line, appended to on
+// every sample and periodically rewritten by Compact to drop anything
+// older than 24h — Weather.pollOnce already prunes the same cutoff from
+// the in-memory slice and only ever needs the last 24h back from Load.
+type fileHistoryStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func newFileHistoryStore(path string) *fileHistoryStore {
+	return &fileHistoryStore{path: path}
+}
+
+func (s *fileHistoryStore) Append(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(e)
+}
+
+func (s *fileHistoryStore) Load(since time.Time) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Entry
+	dec := json.NewDecoder(f)
+	for {
+		var e Entry
+		if err := dec.Decode(&e); err != nil {
+			if err == io.EOF {
+				break
+			}
+			// A truncated trailing line (e.g. from a crash mid-write) is
+			// not fatal: return everything readable up to that point.
+			break
+		}
+		if !e.Time.Before(since) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// Compact rewrites the file keeping only entries at or after since,
+// writing to a temp file and renaming over the original so a crash
+// mid-compact can't leave a truncated file behind.
+func (s *fileHistoryStore) Compact(since time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var keep []Entry
+	dec := json.NewDecoder(f)
+	for {
+		var e Entry
+		if err := dec.Decode(&e); err != nil {
+			break // EOF, or a truncated trailing line: stop reading either way
+		}
+		if !e.Time.Before(since) {
+			keep = append(keep, e)
+		}
+	}
+	f.Close()
+
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(tmp)
+	for _, e := range keep {
+		if err := enc.Encode(e); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path)
+}
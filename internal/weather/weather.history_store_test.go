@@ -0,0 +1,53 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package weather
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestFileHistoryStoreCompact confirms Compact drops entries older than
+// since and keeps the rest, so the backing file doesn't grow without bound.
+func TestFileHistoryStoreCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), historyFilename)
+	s := newFileHistoryStore(path)
+
+	now := time.Now()
+	old := Entry{Time: now.Add(-48 * time.Hour), TempC: -5}
+	recent := Entry{Time: now.Add(-1 * time.Hour), TempC: 10}
+
+	if err := s.Append(old); err != nil {
+		t.Fatalf("Append(old): %v", err)
+	}
+	if err := s.Append(recent); err != nil {
+		t.Fatalf("Append(recent): %v", err)
+	}
+
+	cutoff := now.Add(-24 * time.Hour)
+	if err := s.Compact(cutoff); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+
+	got, err := s.Load(time.Time{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(got) != 1 || got[0].TempC != recent.TempC {
+		t.Errorf("after Compact, Load returned %+v, want only %+v", got, recent)
+	}
+}
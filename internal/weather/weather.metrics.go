@@ -0,0 +1,51 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package weather
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// weatherCollector implements prometheus.Collector, rendering the last
+// saved outdoor temperature and the running sample count so /metrics never
+// disagrees with /api/history.
+type weatherCollector struct {
+	w *Weather
+}
+
+func (c *weatherCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+func (c *weatherCollector) Collect(ch chan<- prometheus.Metric) {
+	if last := c.w.LastSaved(); last != nil {
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("weather_outdoor_temp_c", "Last saved outdoor temperature, in degrees C.", nil, nil),
+			prometheus.GaugeValue, last.TempC)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc("weather_samples_total", "Total outdoor temperature samples saved since startup (including replayed history).", nil, nil),
+		prometheus.CounterValue, float64(c.w.samplesTotal.Load()))
+}
+
+// serveMetrics renders a fresh registry on every scrape, same as dx2w's
+// handleMetrics, since there's no long-lived registry to keep in sync.
+func (w *Weather) serveMetrics(rw http.ResponseWriter, r *http.Request) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(&weatherCollector{w: w})
+	promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP(rw, r)
+}
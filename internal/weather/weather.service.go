@@ -21,10 +21,14 @@ import (
 	"burlo/v2/internal/events"
 	"burlo/v2/pkg/eventbus"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"net/http"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -50,10 +54,14 @@ type Weather struct {
 	poll        time.Duration
 	threshold   float64 // delta in degC that triggers save+publish
 	dx2wService *dx2w.HistoryService
+	store       HistoryStore
 
-	mu        sync.RWMutex
-	history   []Entry
-	lastSaved *Entry
+	mu          sync.RWMutex
+	history     []Entry
+	lastSaved   *Entry
+	lastCompact time.Time
+
+	samplesTotal atomic.Int64 // backs the weather_samples_total counter
 }
 
 // New creates a Weather service. poll is how often to poll the device (e.g. 30s).
@@ -71,13 +79,26 @@ func NewLocalDX2W(dx2wService *dx2w.HistoryService, appConf *config.Config) *Wea
 		threshold = 0.33
 	}
 
-	return &Weather{
+	w := &Weather{
 		eb:          appConf.EventBus,
 		poll:        poll,
 		threshold:   threshold,
 		dx2wService: dx2wService,
+		store:       newFileHistoryStore(filepath.Join(appConf.DataDir, historyFilename)),
 		history:     make([]Entry, 0, 1024),
 	}
+
+	// Replay the last 24h from disk so history/lastSaved survive a restart.
+	if replayed, err := w.store.Load(time.Now().Add(-24 * time.Hour)); err == nil {
+		w.history = append(w.history, replayed...)
+		w.samplesTotal.Store(int64(len(replayed)))
+		if len(replayed) > 0 {
+			last := replayed[len(replayed)-1]
+			w.lastSaved = &last
+		}
+	}
+
+	return w
 }
 
 func (w *Weather) Run(ctx context.Context) {
@@ -127,6 +148,11 @@ func (w *Weather) pollOnce(ctx context.Context) error {
 		entry := Entry{Time: now, TempC: temp}
 		w.history = append(w.history, entry)
 		w.lastSaved = &entry
+		w.samplesTotal.Add(1)
+
+		// Persist before pruning from memory, so a restart can still replay
+		// everything the in-memory slice is about to drop.
+		_ = w.store.Append(entry)
 
 		// prune history older than 24h
 		cutoff := now.Add(-24 * time.Hour)
@@ -137,6 +163,14 @@ func (w *Weather) pollOnce(ctx context.Context) error {
 			w.history = append([]Entry(nil), w.history[idx:]...)
 		}
 
+		// Compact the on-disk file to the same 24h cutoff, but no more than
+		// hourly: rewriting it on every sample would be wasteful, and the
+		// file only grows by one line between compactions either way.
+		if w.lastCompact.IsZero() || now.Sub(w.lastCompact) >= time.Hour {
+			_ = w.store.Compact(cutoff)
+			w.lastCompact = now
+		}
+
 		// publish event (non-blocking best-effort)
 		w.eb.Publish(events.TopicWeather, events.WeatherUpdate{
 			Time:         entry.Time,
@@ -149,9 +183,13 @@ func (w *Weather) pollOnce(ctx context.Context) error {
 
 // ----- HTTP Handler -----
 
-// This service implements http.Handler. It exposes two endpoints:
-//  - GET /            -> HTML page containing a simple chart (uses Chart.js from CDN)
-//  - GET /api/history -> JSON array of history entries
+// This service implements http.Handler. It exposes:
+//  - GET /                 -> HTML page containing a simple chart (uses Chart.js from CDN)
+//  - GET /api/history      -> JSON array of history entries
+//  - GET /api/history.csv  -> CSV export of history entries ("time,temp_c"), optionally
+//                             bounded by ?from=/?to= RFC3339 timestamps
+//  - GET /metrics          -> Prometheus scrape endpoint (weather_outdoor_temp_c,
+//                             weather_samples_total)
 // You can mount it under any path (e.g. /weather/). If mounted under a prefix, the
 // handler will still work because it responds only to the suffixes above.
 
@@ -212,8 +250,8 @@ setInterval(render, 30_000);
 </body>
 </html>`
 
-// ServeHTTP implements http.Handler. It responds to "/" and "/api/history". If you want
-// additional endpoints (csv, svg) you can add them here.
+// ServeHTTP implements http.Handler. It responds to "/", "/api/history",
+// "/api/history.csv", and "/metrics".
 func (w *Weather) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	switch r.URL.Path {
 	case "", "/":
@@ -229,12 +267,57 @@ func (w *Weather) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		enc := json.NewEncoder(rw)
 		enc.SetIndent("", "  ")
 		_ = enc.Encode(hist)
+	case "/api/history.csv":
+		w.serveHistoryCSV(rw, r)
+	case "/metrics":
+		w.serveMetrics(rw, r)
 	default:
 		rw.WriteHeader(http.StatusNotFound)
 		_, _ = rw.Write([]byte("not found"))
 	}
 }
 
+// serveHistoryCSV renders history as RFC 4180 CSV ("time,temp_c"), optionally
+// bounded by ?from= and ?to= RFC3339 timestamps.
+func (w *Weather) serveHistoryCSV(rw http.ResponseWriter, r *http.Request) {
+	var from, to time.Time
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(rw, "bad 'from' parameter: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(rw, "bad 'to' parameter: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+
+	w.mu.RLock()
+	hist := make([]Entry, len(w.history))
+	copy(hist, w.history)
+	w.mu.RUnlock()
+
+	rw.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	cw := csv.NewWriter(rw)
+	_ = cw.Write([]string{"time", "temp_c"})
+	for _, e := range hist {
+		if !from.IsZero() && e.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && e.Time.After(to) {
+			continue
+		}
+		_ = cw.Write([]string{e.Time.Format(time.RFC3339), strconv.FormatFloat(e.TempC, 'f', -1, 64)})
+	}
+	cw.Flush()
+}
+
 // ----- Helpers for integration -----
 
 // LastSaved returns the last saved entry (copy) or nil if none.
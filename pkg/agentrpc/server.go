@@ -0,0 +1,45 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package agentrpc
+
+import (
+	"net"
+
+	"google.golang.org/grpc"
+)
+
+// Serve starts a gRPC server hosting srv as the Agent service on addr and
+// blocks until either it stops on its own or stop is closed.
+func Serve(addr string, srv AgentServer, stop <-chan struct{}) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s := grpc.NewServer()
+	RegisterAgentServer(s, srv)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Serve(lis) }()
+
+	select {
+	case <-stop:
+		s.GracefulStop()
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
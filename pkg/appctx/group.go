@@ -0,0 +1,182 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctx
+
+import (
+	"burlo/v2/pkg/logger"
+	"context"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Registration is one service registered with a Group: Run is started in
+// its own goroutine against the Group's root context; Shutdown, if set, is
+// invoked once that context is cancelled, in ascending Priority order
+// (lower runs first), so safety-critical teardown (forcing an actuator to
+// a safe state) can happen before lower-priority housekeeping.
+type Registration struct {
+	Name     string
+	Run      func(ctx context.Context)
+	Shutdown func(ctx context.Context) error
+	Priority int
+}
+
+// Group coordinates startup and graceful shutdown for a set of Runnable
+// services, unlike pkg/service.Start's unordered goroutine pool: on
+// SIGINT/SIGTERM it cancels the root context, then tears down each
+// registration in priority order (lower first) — waiting for that
+// registration's own Run to return before invoking its Shutdown hook, so
+// a hook never races a still running Run for the same actuator — each
+// step bounded by ShutdownTimeout, logging a summary of which exited
+// cleanly. A second signal escalates to an immediate os.Exit(1) for an
+// operator who can't wait.
+type Group struct {
+	log             *logger.Logger
+	ShutdownTimeout time.Duration
+
+	mu   sync.Mutex
+	regs []Registration
+}
+
+// NewGroup creates a Group with a 5s default ShutdownTimeout.
+func NewGroup() *Group {
+	return &Group{
+		log:             logger.New("appctx"),
+		ShutdownTimeout: 5 * time.Second,
+	}
+}
+
+// WithShutdownTimeout overrides the deadline applied separately to each
+// shutdown step (each wait for a Run to return, and each hook); there is no
+// overall deadline across steps.
+func (g *Group) WithShutdownTimeout(d time.Duration) *Group {
+	g.ShutdownTimeout = d
+	return g
+}
+
+// Register adds a service to the group. Call before Wait.
+func (g *Group) Register(r Registration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.regs = append(g.regs, r)
+}
+
+// Wait starts every registered service, blocks until SIGINT/SIGTERM, then
+// drives shutdown to completion and returns the process exit code (-1 if
+// any service panicked, 0 otherwise).
+func (g *Group) Wait() int {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	g.mu.Lock()
+	regs := append([]Registration(nil), g.regs...)
+	g.mu.Unlock()
+
+	var exitCode atomic.Int32
+	done := make([]chan struct{}, len(regs))
+	for i, r := range regs {
+		finished := make(chan struct{})
+		done[i] = finished
+		go func(r Registration, finished chan struct{}) {
+			defer close(finished)
+			defer func() {
+				if rec := recover(); rec != nil {
+					g.log.Error("%s panicked: %v\n%s", r.Name, rec, debug.Stack())
+					exitCode.Store(-1)
+					cancel()
+				}
+			}()
+			r.Run(ctx)
+		}(r, finished)
+	}
+
+	sig := <-sigs
+	g.log.Info("received signal: %s, shutting down", sig)
+	cancel()
+
+	// A second signal means the operator doesn't want to wait for graceful
+	// shutdown (e.g. it's stuck); get out immediately.
+	go func() {
+		sig2 := <-sigs
+		g.log.Error("received second signal: %s, forcing immediate exit", sig2)
+		os.Exit(1)
+	}()
+
+	clean := g.runShutdownHooks(regs, done)
+	g.log.Info("shutdown summary: %d/%d services exited cleanly", clean, len(regs))
+
+	return int(exitCode.Load())
+}
+
+// runShutdownHooks tears down every registration in ascending Priority
+// order (lower first). For each one it first waits, bounded by
+// ShutdownTimeout, for that registration's own Run to return, and only
+// then invokes its Shutdown hook (itself bounded by ShutdownTimeout) — a
+// hook that forces an actuator to a safe state must never race a still
+// running Run that could write a stale target right after it. It returns
+// the number of registrations whose Run returned cleanly.
+func (g *Group) runShutdownHooks(regs []Registration, done []chan struct{}) int {
+	type entry struct {
+		reg  Registration
+		done chan struct{}
+	}
+	sorted := make([]entry, len(regs))
+	for i, r := range regs {
+		sorted[i] = entry{reg: r, done: done[i]}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].reg.Priority < sorted[j].reg.Priority })
+
+	clean := 0
+	for _, e := range sorted {
+		select {
+		case <-e.done:
+			clean++
+		case <-time.After(g.ShutdownTimeout):
+			g.log.Error("%s: did not exit within shutdown timeout, running shutdown hook anyway", e.reg.Name)
+		}
+
+		if e.reg.Shutdown == nil {
+			continue
+		}
+
+		shutCtx, shutCancel := context.WithTimeout(context.Background(), g.ShutdownTimeout)
+		errCh := make(chan error, 1)
+		go func(r Registration) { errCh <- r.Shutdown(shutCtx) }(e.reg)
+
+		select {
+		case err := <-errCh:
+			if err != nil {
+				g.log.Error("%s: shutdown hook failed: %v", e.reg.Name, err)
+			} else {
+				g.log.Info("%s: shutdown hook complete", e.reg.Name)
+			}
+		case <-shutCtx.Done():
+			g.log.Error("%s: shutdown hook timed out after %s", e.reg.Name, g.ShutdownTimeout)
+		}
+		shutCancel()
+	}
+	return clean
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctx
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestRunShutdownHooksWaitsForRun confirms a registration's Shutdown hook
+// only fires after its own Run has returned, so it can't race a still
+// in-flight Run writing to the same actuator.
+func TestRunShutdownHooksWaitsForRun(t *testing.T) {
+	runDone := make(chan struct{})
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(runDone)
+	}()
+
+	sawRunDone := false
+	reg := Registration{
+		Name:     "svc",
+		Priority: 0,
+		Shutdown: func(ctx context.Context) error {
+			select {
+			case <-runDone:
+				sawRunDone = true
+			default:
+			}
+			return nil
+		},
+	}
+
+	g := NewGroup().WithShutdownTimeout(time.Second)
+	clean := g.runShutdownHooks([]Registration{reg}, []chan struct{}{runDone})
+
+	if !sawRunDone {
+		t.Error("Shutdown hook ran before its own Run returned")
+	}
+	if clean != 1 {
+		t.Errorf("clean = %d, want 1", clean)
+	}
+}
+
+// TestRunShutdownHooksPriorityOrder confirms registrations are torn down in
+// ascending Priority order regardless of registration order.
+func TestRunShutdownHooksPriorityOrder(t *testing.T) {
+	var order []string
+	record := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	regs := []Registration{
+		{Name: "low-priority", Priority: 10, Shutdown: record("low-priority")},
+		{Name: "high-priority", Priority: 0, Shutdown: record("high-priority")},
+	}
+	done := []chan struct{}{make(chan struct{}), make(chan struct{})}
+	close(done[0])
+	close(done[1])
+
+	g := NewGroup().WithShutdownTimeout(time.Second)
+	g.runShutdownHooks(regs, done)
+
+	if len(order) != 2 || order[0] != "high-priority" || order[1] != "low-priority" {
+		t.Errorf("shutdown order = %v, want [high-priority low-priority]", order)
+	}
+}
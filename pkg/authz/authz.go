@@ -0,0 +1,144 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package authz issues and verifies short-lived HS256 JSON Web Tokens
+// signed with a shared secret. It's a minimal, stdlib-only implementation
+// (no external JWT dependency) covering exactly what burlo needs: a scope
+// claim and an expiry, nothing more.
+package authz
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ClockSkew is the leeway allowed when checking a token's exp claim, to
+// tolerate clock drift between the machine that issued it and the one
+// verifying it.
+const ClockSkew = 30 * time.Second
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// Claims is the JWT payload minted and checked by Issuer. Scope selects
+// what the token is good for (e.g. "thermostat"); Verify rejects tokens
+// whose scope doesn't match what the caller asked for.
+type Claims struct {
+	Scope string `json:"scope"`
+	Iat   int64  `json:"iat"`
+	Exp   int64  `json:"exp"`
+}
+
+// Issuer mints and verifies HS256-signed tokens using a shared secret.
+// The zero value is not usable; construct one with NewIssuer.
+type Issuer struct {
+	secret []byte
+}
+
+// NewIssuer builds an Issuer from a shared secret loaded from config.
+func NewIssuer(secret string) *Issuer {
+	return &Issuer{secret: []byte(secret)}
+}
+
+// Issue mints a token with the given scope, valid for ttl from now.
+func (i *Issuer) Issue(scope string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	headerJSON, err := json.Marshal(header{Alg: "HS256", Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(Claims{
+		Scope: scope,
+		Iat:   now.Unix(),
+		Exp:   now.Add(ttl).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := b64encode(headerJSON) + "." + b64encode(claimsJSON)
+	return unsigned + "." + b64encode(i.sign(unsigned)), nil
+}
+
+// Verify checks token's signature and expiry (within ClockSkew) and that
+// its scope matches wantScope, returning the decoded claims on success.
+func (i *Issuer) Verify(token, wantScope string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("authz: malformed token")
+	}
+	unsigned := parts[0] + "." + parts[1]
+
+	sig, err := b64decode(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("authz: invalid signature encoding: %w", err)
+	}
+	if !hmac.Equal(sig, i.sign(unsigned)) {
+		return nil, fmt.Errorf("authz: invalid signature")
+	}
+
+	claimsJSON, err := b64decode(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("authz: invalid claims encoding: %w", err)
+	}
+	var claims Claims
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return nil, fmt.Errorf("authz: invalid claims: %w", err)
+	}
+
+	if claims.Scope != wantScope {
+		return nil, fmt.Errorf("authz: wrong scope: want %q, got %q", wantScope, claims.Scope)
+	}
+	if time.Now().After(time.Unix(claims.Exp, 0).Add(ClockSkew)) {
+		return nil, fmt.Errorf("authz: token expired")
+	}
+	return &claims, nil
+}
+
+func (i *Issuer) sign(unsigned string) []byte {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
+
+func b64encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func b64decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// FromRequest extracts a bearer token from either a "?token=" query param
+// or an "Authorization: Bearer <token>" header, the two places browser
+// apps and native clients can realistically put one. Returns "" if
+// neither is present.
+func FromRequest(r *http.Request) string {
+	if tok := r.URL.Query().Get("token"); tok != "" {
+		return tok
+	}
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package buildinfo holds version metadata stamped in at link time, so a
+// running binary can report exactly what was built and when without a
+// separate release manifest. Build with e.g.:
+//
+//	go build -ldflags "-X burlo/v2/pkg/buildinfo.Version=1.2.3 \
+//	  -X burlo/v2/pkg/buildinfo.GitCommit=$(git rev-parse HEAD) \
+//	  -X burlo/v2/pkg/buildinfo.BuildTime=$(date -u +%FT%TZ)"
+package buildinfo
+
+import "runtime"
+
+// Version, GitCommit and BuildTime are overridden via -ldflags -X at build
+// time. They default to placeholders for unreleased/local builds.
+var (
+	Version   = "dev"
+	GitCommit = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info is the JSON shape returned by the /version endpoint.
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	GOOS      string `json:"goos"`
+	GOARCH    string `json:"goarch"`
+}
+
+// Get snapshots the current build metadata plus the runtime's Go version
+// and target platform.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+	}
+}
@@ -38,6 +38,39 @@ type Bus struct {
 	sendCount        atomic.Int64
 	sendDropCount    atomic.Int64
 	sendReplaceCount atomic.Int64
+
+	// durability: set by EnableDurability, nil otherwise (durability is
+	// entirely opt-in; a Bus with durDir == "" behaves exactly as before).
+	durDir     string
+	durMu      sync.Mutex
+	durable    map[Topic]*topicLog
+	cursorMu   sync.Mutex
+	cursorPath string
+	cursors    map[string]uint64
+
+	// wildcard subscribers (topics containing "*" or ">" tokens) live in a
+	// trie keyed by token, separate from the exact-match subs map; see
+	// wildcard.go. Guarded by mu, same as subs.
+	wildcardRoot *wildcardNode
+	wildcardSubs map[uint64]*wildcardSubInfo
+
+	// bounded per-topic history and catch-up subscriptions; see history.go.
+	historyMu       sync.Mutex
+	historyDefaultN int
+	historyN        map[Topic]int
+	history         map[Topic][]Event
+	catchup         map[Topic]map[uint64]*catchupState // guarded by mu, not historyMu
+}
+
+// Option configures a Bus at construction time.
+type Option func(*Bus)
+
+// WithHistory sets the default bounded history length applied to any topic
+// without its own SetHistory override. n <= 0 disables history by default.
+func WithHistory(n int) Option {
+	return func(b *Bus) {
+		b.historyDefaultN = n
+	}
 }
 
 func (b *Bus) PrintStats() {
@@ -47,12 +80,19 @@ func (b *Bus) PrintStats() {
 	log.Println("send dropped count:", b.sendDropCount.Load())
 }
 
-// New returns an initialized Bus.
-func New() *Bus {
-	return &Bus{
-		subs: make(map[Topic]map[uint64]chan Event),
-		last: make(map[Topic]Event),
+// New returns an initialized Bus, applying any Options (e.g. WithHistory).
+func New(opts ...Option) *Bus {
+	b := &Bus{
+		subs:         make(map[Topic]map[uint64]chan Event),
+		last:         make(map[Topic]Event),
+		wildcardRoot: newWildcardNode(),
+		wildcardSubs: make(map[uint64]*wildcardSubInfo),
+		catchup:      make(map[Topic]map[uint64]*catchupState),
 	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
 }
 
 // Publish publishes ev to topic. It stores ev as the "last" event for the topic.
@@ -77,12 +117,26 @@ func (b *Bus) Publish(topic Topic, ev Event) {
 			chans = append(chans, ch)
 		}
 	}
+	b.collectWildcardMatches(topic, &chans)
+
+	// Feed catch-up subscribers still draining their bounded-history replay;
+	// once a subscriber finishes that replay it moves into b.subs above and
+	// is reached through the normal chans path instead (see history.go).
+	for _, st := range b.catchup[topic] {
+		st.push(ev)
+	}
 	b.mu.Unlock()
 
 	// Send to each subscriber with "replace oldest" semantics
 	for _, ch := range chans {
 		b.publishReplace(ch, ev)
 	}
+
+	b.appendHistory(topic, ev)
+
+	if b.durDir != "" {
+		b.getOrCreateTopicLog(topic).append(ev)
+	}
 }
 
 // publishReplace tries to deliver ev to ch. If ch is full, it removes the existing item (if any)
@@ -126,6 +180,10 @@ func (b *Bus) Subscribe(ctx context.Context, topic Topic, withLast bool) (<-chan
 		return ch, func() {}
 	}
 
+	if isWildcardPattern(topic) {
+		return b.subscribeWildcard(ctx, topic, withLast)
+	}
+
 	ch := make(chan Event, 1)
 	id := atomic.AddUint64(&b.idCounter, 1)
 
@@ -204,5 +262,16 @@ func (b *Bus) Close() {
 	}
 	b.subs = nil
 	b.last = nil
+	for _, info := range b.wildcardSubs {
+		close(info.channel())
+	}
+	b.wildcardRoot = nil
+	b.wildcardSubs = nil
+	for _, m := range b.catchup {
+		for _, st := range m {
+			close(st.ch)
+		}
+	}
+	b.catchup = nil
 	b.mu.Unlock()
 }
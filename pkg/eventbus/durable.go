@@ -0,0 +1,480 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package eventbus
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// maxSegmentBytes rotates the active segment once it grows past this
+	// size; rotated segments are gzipped and kept around for replay.
+	maxSegmentBytes = 2 * 1024 * 1024
+
+	// durableSubBuffer bounds how far a durable subscriber can lag behind
+	// live traffic before its oldest queued event is dropped; replay from
+	// disk (via offset) is how a subscriber catches back up.
+	durableSubBuffer = 64
+
+	cursorFilename = "eventbus_cursors.json"
+)
+
+// Envelope is one durable log record: the event plus its position in the
+// topic's log and when it was published.
+type Envelope struct {
+	Offset    uint64    `json:"offset"`
+	Timestamp time.Time `json:"timestamp"`
+	Event     Event     `json:"event"`
+}
+
+// segIndexEntry maps an offset to its byte position in the active segment.
+type segIndexEntry struct {
+	Offset uint64
+	Pos    int64
+}
+
+type durableListener struct {
+	ch chan Envelope
+}
+
+// topicLog is one topic's append-only log: a single growing "active"
+// segment file plus any number of older, gzip-compressed rotated segments.
+type topicLog struct {
+	mu  sync.Mutex
+	dir string
+
+	topic      Topic
+	file       *os.File // nil if the log failed to open; append() then runs in-memory-only
+	nextOffset uint64
+	segStart   uint64
+	curBytes   int64
+	index      []segIndexEntry // offset -> byte position, active segment only
+
+	listeners map[*durableListener]bool
+}
+
+func activeSegmentPath(dir string, topic Topic) string {
+	return filepath.Join(dir, string(topic)+".active.jsonl")
+}
+
+func rotatedSegmentPath(dir string, topic Topic, first, last uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%s.%020d-%020d.jsonl.gz", topic, first, last))
+}
+
+// openTopicLog opens (or creates) the active segment for topic, replaying
+// it to rebuild the in-memory offset index and next-offset counter, and
+// scans existing rotated segments just to find where offsets left off.
+func openTopicLog(dir string, topic Topic) (*topicLog, error) {
+	tl := &topicLog{
+		dir:       dir,
+		topic:     topic,
+		listeners: make(map[*durableListener]bool),
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("mkdir %s: %w", dir, err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, string(topic)+".*-*.jsonl.gz"))
+	sort.Strings(matches)
+	if len(matches) > 0 {
+		var first, last uint64
+		base := filepath.Base(matches[len(matches)-1])
+		fmt.Sscanf(base, string(topic)+".%020d-%020d.jsonl.gz", &first, &last)
+		tl.nextOffset = last + 1
+	}
+	tl.segStart = tl.nextOffset
+
+	path := activeSegmentPath(dir, topic)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+
+	// Replay the active segment to rebuild the index and offset counter.
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var pos int64
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var rec Envelope
+		if err := json.Unmarshal(line, &rec); err == nil {
+			tl.index = append(tl.index, segIndexEntry{Offset: rec.Offset, Pos: pos})
+			if rec.Offset >= tl.nextOffset {
+				tl.nextOffset = rec.Offset + 1
+			}
+		}
+		pos += int64(len(line)) + 1
+	}
+	tl.curBytes = pos
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		file.Close()
+		return nil, err
+	}
+	tl.file = file
+
+	return tl, nil
+}
+
+// append writes ev to the log, rotating the active segment if it has grown
+// too large, and fans it out to any live durable subscribers.
+func (tl *topicLog) append(ev Event) uint64 {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	rec := Envelope{Offset: tl.nextOffset, Timestamp: time.Now(), Event: ev}
+	offset := rec.Offset
+
+	if tl.file != nil {
+		line, err := json.Marshal(rec)
+		if err == nil {
+			line = append(line, '\n')
+			if _, err := tl.file.Write(line); err == nil {
+				tl.index = append(tl.index, segIndexEntry{Offset: rec.Offset, Pos: tl.curBytes})
+				tl.curBytes += int64(len(line))
+			} else {
+				log.Printf("[error] eventbus: failed to append to %q log: %v", tl.topic, err)
+			}
+		}
+	}
+
+	tl.nextOffset++
+	if tl.curBytes >= maxSegmentBytes {
+		tl.rotate()
+	}
+
+	for l := range tl.listeners {
+		sendDropOldest(l.ch, rec)
+	}
+
+	return offset
+}
+
+// sendDropOldest delivers ev to ch, dropping the oldest queued item first if
+// ch is full rather than blocking the publisher on a slow durable subscriber.
+func sendDropOldest(ch chan Envelope, ev Envelope) {
+	select {
+	case ch <- ev:
+		return
+	default:
+	}
+	select {
+	case <-ch:
+	default:
+	}
+	select {
+	case ch <- ev:
+	default:
+	}
+}
+
+// rotate gzips the current active segment under a name encoding its offset
+// range and starts a fresh, empty active segment. Caller must hold tl.mu.
+func (tl *topicLog) rotate() {
+	path := activeSegmentPath(tl.dir, tl.topic)
+	tl.file.Close()
+
+	if tl.nextOffset > tl.segStart {
+		if err := gzipFile(path, rotatedSegmentPath(tl.dir, tl.topic, tl.segStart, tl.nextOffset-1)); err != nil {
+			log.Printf("[error] eventbus: failed to rotate %q log: %v", tl.topic, err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0o644)
+	if err != nil {
+		log.Printf("[error] eventbus: failed to start new segment for %q: %v", tl.topic, err)
+		tl.file = nil
+	} else {
+		tl.file = file
+	}
+	tl.index = nil
+	tl.curBytes = 0
+	tl.segStart = tl.nextOffset
+}
+
+func gzipFile(srcPath, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmpPath := dstPath + ".tmp"
+	dst, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, bufio.NewReader(src)); err != nil {
+		gz.Close()
+		dst.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, dstPath); err != nil {
+		return err
+	}
+	return os.Remove(srcPath)
+}
+
+// readFromLocked returns every record with Offset >= fromOffset, reading
+// through rotated (gzip) segments in order and finishing with the active
+// segment. Caller must hold tl.mu.
+func (tl *topicLog) readFromLocked(fromOffset uint64) []Envelope {
+	var out []Envelope
+
+	matches, _ := filepath.Glob(filepath.Join(tl.dir, string(tl.topic)+".*-*.jsonl.gz"))
+	sort.Strings(matches)
+	for _, path := range matches {
+		var first, last uint64
+		fmt.Sscanf(filepath.Base(path), string(tl.topic)+".%020d-%020d.jsonl.gz", &first, &last)
+		if last < fromOffset {
+			continue
+		}
+		out = append(out, readGzipSegment(path, fromOffset)...)
+	}
+
+	if tl.file == nil {
+		return out
+	}
+
+	startPos := int64(0)
+	if fromOffset > tl.segStart {
+		i := sort.Search(len(tl.index), func(i int) bool { return tl.index[i].Offset >= fromOffset })
+		if i < len(tl.index) {
+			startPos = tl.index[i].Pos
+		} else {
+			startPos = tl.curBytes
+		}
+	}
+
+	if _, err := tl.file.Seek(startPos, io.SeekStart); err != nil {
+		log.Printf("[error] eventbus: seek active segment for %q: %v", tl.topic, err)
+		return out
+	}
+	scanner := bufio.NewScanner(tl.file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec Envelope
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err == nil && rec.Offset >= fromOffset {
+			out = append(out, rec)
+		}
+	}
+	tl.file.Seek(0, io.SeekEnd)
+
+	return out
+}
+
+func readGzipSegment(path string, fromOffset uint64) []Envelope {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Printf("[error] eventbus: open rotated segment %s: %v", path, err)
+		return nil
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		log.Printf("[error] eventbus: open gzip segment %s: %v", path, err)
+		return nil
+	}
+	defer gz.Close()
+
+	var out []Envelope
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec Envelope
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err == nil && rec.Offset >= fromOffset {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+// EnableDurability turns on append-only, gzip-rotated persistence for every
+// topic published after this call, plus per-subscriber cursor tracking.
+// Must be called before Publish/SubscribeDurable are used if durability is
+// wanted; a Bus that never calls this behaves exactly as it did before.
+func (b *Bus) EnableDurability(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	b.durMu.Lock()
+	b.durDir = dir
+	b.durable = make(map[Topic]*topicLog)
+	b.durMu.Unlock()
+
+	b.cursorMu.Lock()
+	defer b.cursorMu.Unlock()
+	b.cursorPath = filepath.Join(dir, cursorFilename)
+	b.cursors = loadCursors(b.cursorPath)
+	return nil
+}
+
+func (b *Bus) getOrCreateTopicLog(topic Topic) *topicLog {
+	b.durMu.Lock()
+	defer b.durMu.Unlock()
+	if tl, ok := b.durable[topic]; ok {
+		return tl
+	}
+	tl, err := openTopicLog(b.durDir, topic)
+	if err != nil {
+		log.Printf("[error] eventbus: failed to open durable log for topic %q: %v", topic, err)
+		tl = &topicLog{topic: topic, dir: b.durDir, listeners: make(map[*durableListener]bool)}
+	}
+	b.durable[topic] = tl
+	return tl
+}
+
+// SubscribeDurable attaches to topic's durable log from fromOffset (0
+// replays everything retained), delivering the replay backlog and live
+// events on the returned channel (the two can interleave; see the note in
+// the body). subscriberID identifies the caller
+// for Ack/Cursor purposes, so it can resume from where it left off after a
+// restart: pass the result of Cursor(topic, subscriberID) as fromOffset.
+//
+// The subscription is torn down automatically when ctx is canceled, or
+// immediately via the returned cancel func.
+func (b *Bus) SubscribeDurable(ctx context.Context, topic Topic, subscriberID string, fromOffset uint64) (<-chan Envelope, func(offset uint64), func()) {
+	tl := b.getOrCreateTopicLog(topic)
+	ch := make(chan Envelope, durableSubBuffer)
+	l := &durableListener{ch: ch}
+
+	tl.mu.Lock()
+	backlog := tl.readFromLocked(fromOffset)
+	tl.listeners[l] = true
+	tl.mu.Unlock()
+
+	unsub := func() {
+		tl.mu.Lock()
+		delete(tl.listeners, l)
+		tl.mu.Unlock()
+	}
+
+	go func() {
+		// Note: the backlog snapshot and the listener registration above
+		// happen under one tl.mu hold, so no record is in both. Live
+		// records, though, go straight into ch from append and can arrive
+		// interleaved with the backlog still being sent here, or displace
+		// a queued backlog record when ch is full; Envelope.Offset gives
+		// each record's position in the log.
+		for _, env := range backlog {
+			select {
+			case ch <- env:
+			case <-ctx.Done():
+				return
+			}
+		}
+		<-ctx.Done()
+		unsub()
+	}()
+
+	ack := func(offset uint64) {
+		b.saveCursor(topic, subscriberID, offset)
+	}
+
+	return ch, ack, unsub
+}
+
+// OffsetSince returns the offset of the first retained record for topic
+// published at or after since, so callers can replay a time window instead
+// of an offset. ok is false if no such record is retained.
+func (b *Bus) OffsetSince(topic Topic, since time.Time) (offset uint64, ok bool) {
+	tl := b.getOrCreateTopicLog(topic)
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	for _, rec := range tl.readFromLocked(0) {
+		if !rec.Timestamp.Before(since) {
+			return rec.Offset, true
+		}
+	}
+	return 0, false
+}
+
+func cursorKey(topic Topic, subscriberID string) string {
+	return string(topic) + "|" + subscriberID
+}
+
+// Cursor returns the last offset Ack'd by subscriberID on topic, persisted
+// across restarts.
+func (b *Bus) Cursor(topic Topic, subscriberID string) (uint64, bool) {
+	b.cursorMu.Lock()
+	defer b.cursorMu.Unlock()
+	off, ok := b.cursors[cursorKey(topic, subscriberID)]
+	return off, ok
+}
+
+func (b *Bus) saveCursor(topic Topic, subscriberID string, offset uint64) {
+	b.cursorMu.Lock()
+	defer b.cursorMu.Unlock()
+	if b.cursors == nil {
+		b.cursors = make(map[string]uint64)
+	}
+	b.cursors[cursorKey(topic, subscriberID)] = offset
+	if b.cursorPath != "" {
+		if err := persistCursors(b.cursorPath, b.cursors); err != nil {
+			log.Printf("[error] eventbus: failed to persist cursors: %v", err)
+		}
+	}
+}
+
+func persistCursors(path string, cursors map[string]uint64) error {
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(cursors); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func loadCursors(path string) map[string]uint64 {
+	cursors := make(map[string]uint64)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cursors
+	}
+	if err := json.Unmarshal(data, &cursors); err != nil {
+		log.Printf("[error] eventbus: failed to decode cursor file %s: %v", path, err)
+	}
+	return cursors
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package eventbus
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGzipFileRoundTrip compresses a segment file and confirms the result
+// decompresses back to the original bytes, and that gzipFile removes the
+// now-redundant source file once the compressed copy is safely in place.
+func TestGzipFileRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "segment.log")
+	dstPath := filepath.Join(dir, "segment.log.gz")
+
+	want := []byte(`{"offset":0,"event":"hello"}` + "\n" + `{"offset":1,"event":"world"}` + "\n")
+	if err := os.WriteFile(srcPath, want, 0644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	if err := gzipFile(srcPath, dstPath); err != nil {
+		t.Fatalf("gzipFile: %v", err)
+	}
+
+	f, err := os.Open(dstPath)
+	if err != nil {
+		t.Fatalf("open compressed file: %v", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	defer gz.Close()
+
+	got, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("read decompressed: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("round trip mismatch: got %q, want %q", got, want)
+	}
+
+	if _, err := os.Stat(srcPath); !os.IsNotExist(err) {
+		t.Errorf("gzipFile should remove the source file, stat returned: %v", err)
+	}
+}
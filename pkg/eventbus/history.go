@@ -0,0 +1,218 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// catchupState buffers events published for a topic while a
+// SubscribeWithHistory call is still draining its bounded-history replay.
+// Unlike the size-1 channels used elsewhere, this buffer is unbounded so
+// nothing published during the (short) catch-up phase is lost.
+type catchupState struct {
+	mu  sync.Mutex
+	buf []Event
+	ch  chan Event
+}
+
+func (s *catchupState) push(ev Event) {
+	s.mu.Lock()
+	s.buf = append(s.buf, ev)
+	s.mu.Unlock()
+}
+
+func (s *catchupState) drain() []Event {
+	s.mu.Lock()
+	out := s.buf
+	s.buf = nil
+	s.mu.Unlock()
+	return out
+}
+
+// SetHistory sets the bounded history length retained for topic, overriding
+// the default from WithHistory. n <= 0 disables and discards history for
+// this topic.
+func (b *Bus) SetHistory(topic Topic, n int) {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+	if b.historyN == nil {
+		b.historyN = make(map[Topic]int)
+	}
+	b.historyN[topic] = n
+
+	if n <= 0 {
+		delete(b.history, topic)
+		return
+	}
+	if buf, ok := b.history[topic]; ok && len(buf) > n {
+		b.history[topic] = append([]Event(nil), buf[len(buf)-n:]...)
+	}
+}
+
+// historyCap returns the bounded history length configured for topic.
+func (b *Bus) historyCap(topic Topic) int {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+	if n, ok := b.historyN[topic]; ok {
+		return n
+	}
+	return b.historyDefaultN
+}
+
+// appendHistory records ev in topic's bounded history, trimming the oldest
+// entries once the configured length is exceeded.
+func (b *Bus) appendHistory(topic Topic, ev Event) {
+	n := b.historyCap(topic)
+	if n <= 0 {
+		return
+	}
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+	if b.history == nil {
+		b.history = make(map[Topic][]Event)
+	}
+	buf := append(b.history[topic], ev)
+	if len(buf) > n {
+		buf = buf[len(buf)-n:]
+	}
+	b.history[topic] = buf
+}
+
+// snapshotHistory returns a copy of topic's currently stored history, oldest
+// first, capped to the most recent n entries (n <= 0 means no cap).
+func (b *Bus) snapshotHistory(topic Topic, n int) []Event {
+	b.historyMu.Lock()
+	buf := b.history[topic]
+	if n > 0 && len(buf) > n {
+		buf = buf[len(buf)-n:]
+	}
+	out := make([]Event, len(buf))
+	copy(out, buf)
+	b.historyMu.Unlock()
+	return out
+}
+
+// SubscribeWithHistory subscribes to topic like Subscribe, but first
+// delivers up to n of the most recent retained events (oldest first) over
+// an unbounded, blocking catch-up path that honors ctx, then atomically
+// switches the subscriber over to the normal non-blocking publishReplace
+// behavior. Events published while the catch-up replay is in progress are
+// buffered and flushed right after, so nothing in between is lost.
+//
+// Requires history to have been enabled for topic, via WithHistory at
+// construction or SetHistory; with no history retained, this behaves the
+// same as Subscribe(ctx, topic, false).
+func (b *Bus) SubscribeWithHistory(ctx context.Context, topic Topic, n int) (<-chan Event, func()) {
+	if b.closed.Load() {
+		ch := make(chan Event)
+		close(ch)
+		return ch, func() {}
+	}
+
+	ch := make(chan Event, 1)
+	id := atomic.AddUint64(&b.idCounter, 1)
+	st := &catchupState{ch: ch}
+
+	backlog := b.snapshotHistory(topic, n)
+
+	b.mu.Lock()
+	if b.catchup[topic] == nil {
+		b.catchup[topic] = make(map[uint64]*catchupState)
+	}
+	b.catchup[topic][id] = st
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	unsub := func() {
+		select {
+		case <-done:
+		default:
+			close(done)
+		}
+	}
+
+	go func() {
+		for _, ev := range backlog {
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				b.removeCatchup(topic, id)
+				close(ch)
+				return
+			case <-done:
+				b.removeCatchup(topic, id)
+				close(ch)
+				return
+			}
+		}
+
+		// Atomically hand this subscriber off from the catch-up buffer to
+		// the live subs map; any event published from this point on reaches
+		// it through the normal Publish path instead of st.push.
+		b.mu.Lock()
+		if cm, ok := b.catchup[topic]; ok {
+			delete(cm, id)
+			if len(cm) == 0 {
+				delete(b.catchup, topic)
+			}
+		}
+		if b.subs[topic] == nil {
+			b.subs[topic] = make(map[uint64]chan Event)
+		}
+		b.subs[topic][id] = ch
+		b.mu.Unlock()
+
+		// Flush whatever arrived while we were replaying the backlog above.
+		for _, ev := range st.drain() {
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+			case <-done:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+
+		b.mu.Lock()
+		if m, ok := b.subs[topic]; ok {
+			delete(m, id)
+			if len(m) == 0 {
+				delete(b.subs, topic)
+			}
+		}
+		b.mu.Unlock()
+		close(ch)
+	}()
+
+	return ch, unsub
+}
+
+func (b *Bus) removeCatchup(topic Topic, id uint64) {
+	b.mu.Lock()
+	if cm, ok := b.catchup[topic]; ok {
+		delete(cm, id)
+		if len(cm) == 0 {
+			delete(b.catchup, topic)
+		}
+	}
+	b.mu.Unlock()
+}
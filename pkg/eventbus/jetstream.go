@@ -0,0 +1,204 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// BridgeConfig controls how a JetStreamBridge maps bus topics onto
+// JetStream subjects and consumers.
+type BridgeConfig struct {
+	// StreamName is the JetStream stream the bridge publishes into and
+	// reads durable consumers from. The stream must already exist (or be
+	// created by the caller) with a subject filter covering SubjectPrefix+">".
+	StreamName string
+	// SubjectPrefix is prepended to a topic to form its JetStream subject,
+	// e.g. "burlo.events." + "weather.current" -> "burlo.events.weather.current".
+	SubjectPrefix string
+	// PublishTimeout bounds how long a single mirrored publish may block.
+	// Defaults to 2s when zero.
+	PublishTimeout time.Duration
+}
+
+// JetStreamBridge mirrors Bus.Publish calls into a JetStream stream so
+// events survive process restarts, and lets subscribers catch up on
+// missed events via durable consumers instead of only seeing "latest".
+//
+// A bridge is best-effort: any JetStream failure is logged and otherwise
+// ignored so the in-memory bus's existing non-blocking semantics in
+// publishReplace are never compromised by a slow or unavailable NATS
+// server.
+type JetStreamBridge struct {
+	bus    *Bus
+	js     nats.JetStreamContext
+	cfg    BridgeConfig
+	cancel context.CancelFunc
+
+	publishCount atomic.Int64
+	ackCount     atomic.Int64
+	nackCount    atomic.Int64
+}
+
+// NewJetStreamBridge wraps bus so every Publish is also mirrored to
+// JetStream, and replays the last persisted message per subject into
+// bus.last so GetLast/withLast subscribers see it immediately after a
+// restart rather than waiting for the next publish.
+func NewJetStreamBridge(bus *Bus, js nats.JetStreamContext, cfg BridgeConfig) (*JetStreamBridge, error) {
+	if cfg.PublishTimeout == 0 {
+		cfg.PublishTimeout = 2 * time.Second
+	}
+
+	br := &JetStreamBridge{bus: bus, js: js, cfg: cfg}
+
+	if err := br.replayLast(); err != nil {
+		// Non-fatal: the bridge still mirrors future publishes even if it
+		// couldn't backfill bus.last from history on startup.
+		log.Printf("[eventbus] jetstream replay failed, continuing without backfill: %v", err)
+	}
+
+	return br, nil
+}
+
+// subject returns the JetStream subject a topic is mirrored to.
+func (br *JetStreamBridge) subject(topic Topic) string {
+	return br.cfg.SubjectPrefix + string(topic)
+}
+
+// Publish publishes ev on the in-memory bus (preserving all of its
+// existing semantics) and best-effort mirrors it to JetStream.
+func (br *JetStreamBridge) Publish(topic Topic, ev Event) {
+	br.bus.Publish(topic, ev)
+
+	data, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("[eventbus] jetstream marshal failed for topic %q: %v", topic, err)
+		br.nackCount.Add(1)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), br.cfg.PublishTimeout)
+	defer cancel()
+
+	_, err = br.js.Publish(br.subject(topic), data, nats.Context(ctx))
+	if err != nil {
+		log.Printf("[eventbus] jetstream publish failed for topic %q: %v", topic, err)
+		br.nackCount.Add(1)
+		return
+	}
+	br.publishCount.Add(1)
+	br.ackCount.Add(1)
+}
+
+// replayLast fetches the most recent message per subject covered by the
+// stream and loads it into bus.last, so a freshly-started process doesn't
+// report "no last value" for a topic that was in fact published before
+// the restart.
+func (br *JetStreamBridge) replayLast() error {
+	sub, err := br.js.PullSubscribe(br.cfg.SubjectPrefix+">", "", nats.BindStream(br.cfg.StreamName))
+	if err != nil {
+		return fmt.Errorf("subscribe for replay: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	// Messages arrive in stream order, so each one overwrites the previous
+	// value for its subject and the most recent one is what remains.
+	for {
+		msgs, err := sub.Fetch(1, nats.MaxWait(500*time.Millisecond))
+		if err != nil {
+			// Timeout/no more messages is the normal way this loop ends.
+			break
+		}
+		for _, msg := range msgs {
+			var ev Event
+			if err := json.Unmarshal(msg.Data, &ev); err == nil {
+				topic := Topic(msg.Subject[len(br.cfg.SubjectPrefix):])
+				br.bus.mu.Lock()
+				br.bus.last[topic] = ev
+				br.bus.mu.Unlock()
+			}
+			msg.Ack()
+		}
+	}
+	return nil
+}
+
+// SubscribeDurable consumes from a durable JetStream consumer for topic so
+// a late-starting subscriber catches up on every missed event, not just
+// the latest one. Unlike Bus.Subscribe/publishReplace, delivery here
+// blocks: a fetched message is only Ack'd once it has actually been sent
+// on the returned channel, so a consumer that's briefly slower than the
+// backlog just applies back-pressure to the next Fetch instead of losing
+// messages to a replace. The subscription stops when ctx is canceled or
+// unsubscribe() is called.
+func (br *JetStreamBridge) SubscribeDurable(ctx context.Context, topic Topic, durableName string) (<-chan Event, func(), error) {
+	sub, err := br.js.PullSubscribe(br.subject(topic), durableName, nats.BindStream(br.cfg.StreamName))
+	if err != nil {
+		return nil, nil, fmt.Errorf("durable subscribe to %q: %w", topic, err)
+	}
+
+	ch := make(chan Event, 1)
+	subCtx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		defer sub.Unsubscribe()
+		defer close(ch)
+		for {
+			select {
+			case <-subCtx.Done():
+				return
+			default:
+			}
+
+			msgs, err := sub.Fetch(1, nats.MaxWait(time.Second))
+			if err != nil {
+				continue // timeout waiting for the next message; keep polling
+			}
+			for _, msg := range msgs {
+				var ev Event
+				if err := json.Unmarshal(msg.Data, &ev); err != nil {
+					log.Printf("[eventbus] jetstream durable unmarshal failed for topic %q: %v", topic, err)
+					msg.Nak()
+					br.nackCount.Add(1)
+					continue
+				}
+
+				select {
+				case ch <- ev:
+					msg.Ack()
+					br.ackCount.Add(1)
+				case <-subCtx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, cancel, nil
+}
+
+// Stats returns the JetStream-path publish/ack/nack counters, alongside
+// the bus's own in-memory counters.
+func (br *JetStreamBridge) Stats() (publish, ack, nack int64) {
+	return br.publishCount.Load(), br.ackCount.Load(), br.nackCount.Load()
+}
@@ -0,0 +1,220 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package eventbus
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// wildcardNode is one token's position in the subscriber trie. A pattern
+// like "thermostat.*.setpoint" walks children["thermostat"].star.children["setpoint"],
+// and a pattern ending in ">" registers its subscribers in tailSubs on the
+// node reached just before the ">" token, matching any one-or-more further
+// tokens the way NATS subjects do.
+type wildcardNode struct {
+	children map[string]*wildcardNode
+	star     *wildcardNode
+	subs     map[uint64]chan Event
+	tailSubs map[uint64]chan Event
+}
+
+func newWildcardNode() *wildcardNode {
+	return &wildcardNode{children: make(map[string]*wildcardNode)}
+}
+
+// wildcardSubInfo lets unsubscribe remove a subscriber's channel without
+// re-walking the trie from the root.
+type wildcardSubInfo struct {
+	node *wildcardNode
+	ch   chan Event
+	tail bool
+}
+
+// isWildcardPattern reports whether topic contains a "*" or ">" token and
+// should be routed through the wildcard trie instead of the exact-match map.
+func isWildcardPattern(topic Topic) bool {
+	for _, tok := range strings.Split(string(topic), ".") {
+		if tok == "*" || tok == ">" {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTopic reports whether topic matches pattern under NATS-style "."
+// token rules: "*" matches exactly one token, ">" matches one or more
+// trailing tokens and must be the last token in pattern.
+func matchTopic(pattern, topic Topic) bool {
+	ptoks := strings.Split(string(pattern), ".")
+	ttoks := strings.Split(string(topic), ".")
+	for i, pt := range ptoks {
+		if pt == ">" {
+			return i < len(ttoks)
+		}
+		if i >= len(ttoks) {
+			return false
+		}
+		if pt != "*" && pt != ttoks[i] {
+			return false
+		}
+	}
+	return len(ptoks) == len(ttoks)
+}
+
+// collectWildcardMatches appends the channels of every wildcard subscriber
+// whose pattern matches topic to out. Caller must hold b.mu.
+func (b *Bus) collectWildcardMatches(topic Topic, out *[]chan Event) {
+	if b.wildcardRoot == nil {
+		return
+	}
+	tokens := strings.Split(string(topic), ".")
+
+	var walk func(node *wildcardNode, idx int)
+	walk = func(node *wildcardNode, idx int) {
+		if node == nil {
+			return
+		}
+		if idx == len(tokens) {
+			for _, ch := range node.subs {
+				*out = append(*out, ch)
+			}
+			return
+		}
+		for _, ch := range node.tailSubs {
+			*out = append(*out, ch)
+		}
+		if child, ok := node.children[tokens[idx]]; ok {
+			walk(child, idx+1)
+		}
+		walk(node.star, idx+1)
+	}
+	walk(b.wildcardRoot, 0)
+}
+
+// subscribeWildcard implements Subscribe for patterns containing "*" or ">".
+// It registers the subscriber's channel in the trie (see collectWildcardMatches)
+// and, for withLast, replays every currently-stored last event whose topic
+// matches the pattern, in topic-sorted order, one publishReplace call each.
+func (b *Bus) subscribeWildcard(ctx context.Context, pattern Topic, withLast bool) (<-chan Event, func()) {
+	ch := make(chan Event, 1)
+	id := atomic.AddUint64(&b.idCounter, 1)
+
+	b.mu.Lock()
+	info := b.registerWildcardLocked(pattern, id, ch)
+
+	var matches []Topic
+	if withLast {
+		for t := range b.last {
+			if matchTopic(pattern, t) {
+				matches = append(matches, t)
+			}
+		}
+		sort.Slice(matches, func(i, j int) bool { return matches[i] < matches[j] })
+	}
+	last := make([]Event, len(matches))
+	for i, t := range matches {
+		last[i] = b.last[t]
+	}
+	b.mu.Unlock()
+
+	for _, ev := range last {
+		b.publishReplace(ch, ev)
+	}
+
+	done := make(chan struct{})
+	unsub := func() {
+		select {
+		case <-done:
+		default:
+			close(done)
+		}
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+
+		b.mu.Lock()
+		if info.tail {
+			delete(info.node.tailSubs, id)
+		} else {
+			delete(info.node.subs, id)
+		}
+		delete(b.wildcardSubs, id)
+		b.mu.Unlock()
+		close(ch)
+	}()
+
+	return ch, unsub
+}
+
+// registerWildcardLocked walks/creates the trie path for pattern and adds
+// id/ch at the terminal node. Caller must hold b.mu.
+func (b *Bus) registerWildcardLocked(pattern Topic, id uint64, ch chan Event) *wildcardSubInfo {
+	node := b.wildcardRoot
+	tokens := strings.Split(string(pattern), ".")
+
+	for i, tok := range tokens {
+		if tok == ">" {
+			if node.tailSubs == nil {
+				node.tailSubs = make(map[uint64]chan Event)
+			}
+			node.tailSubs[id] = ch
+			info := &wildcardSubInfo{node: node, ch: ch, tail: true}
+			b.wildcardSubs[id] = info
+			return info
+		}
+
+		var child *wildcardNode
+		if tok == "*" {
+			if node.star == nil {
+				node.star = newWildcardNode()
+			}
+			child = node.star
+		} else {
+			c, ok := node.children[tok]
+			if !ok {
+				c = newWildcardNode()
+				node.children[tok] = c
+			}
+			child = c
+		}
+		node = child
+
+		if i == len(tokens)-1 {
+			if node.subs == nil {
+				node.subs = make(map[uint64]chan Event)
+			}
+			node.subs[id] = ch
+			info := &wildcardSubInfo{node: node, ch: ch, tail: false}
+			b.wildcardSubs[id] = info
+			return info
+		}
+	}
+
+	// unreachable: tokens is never empty (strings.Split always returns at
+	// least one element), so the loop above always returns.
+	panic("eventbus: empty wildcard pattern")
+}
+
+func (info *wildcardSubInfo) channel() chan Event {
+	return info.ch
+}
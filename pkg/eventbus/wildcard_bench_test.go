@@ -0,0 +1,94 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// BenchmarkPublishExact measures Publish with only exact-match subscribers,
+// as a baseline for BenchmarkPublishWildcard below.
+func BenchmarkPublishExact(b *testing.B) {
+	bus := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, _ := bus.Subscribe(ctx, Topic("thermostat.livingroom.setpoint"), false)
+	go drain(ch)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bus.Publish(Topic("thermostat.livingroom.setpoint"), i)
+	}
+}
+
+// BenchmarkPublishWildcard measures Publish when the topic must walk the
+// wildcard trie to find matching subscribers.
+func BenchmarkPublishWildcard(b *testing.B) {
+	bus := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, _ := bus.Subscribe(ctx, Topic("thermostat.*.setpoint"), false)
+	go drain(ch)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bus.Publish(Topic("thermostat.livingroom.setpoint"), i)
+	}
+}
+
+// BenchmarkPublishWildcardManyTopics measures the trie walk cost when many
+// distinct rooms publish against a single "*" subscriber.
+func BenchmarkPublishWildcardManyTopics(b *testing.B) {
+	bus := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, _ := bus.Subscribe(ctx, Topic("thermostat.*.setpoint"), false)
+	go drain(ch)
+
+	topics := make([]Topic, 50)
+	for i := range topics {
+		topics[i] = Topic(fmt.Sprintf("thermostat.room%d.setpoint", i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bus.Publish(topics[i%len(topics)], i)
+	}
+}
+
+// BenchmarkPublishNoSubscribers measures the cost of a publish that no
+// pattern (exact or wildcard) matches, the common case when most topics
+// have no wildcard listener at all.
+func BenchmarkPublishNoSubscribers(b *testing.B) {
+	bus := New()
+	ch, _ := bus.Subscribe(context.Background(), Topic("unrelated.>"), false)
+	go drain(ch)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bus.Publish(Topic("thermostat.livingroom.setpoint"), i)
+	}
+}
+
+func drain(ch <-chan Event) {
+	for range ch {
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"context"
+
+	"burlo/v2/pkg/eventbus"
+)
+
+// LogLevelTopic is where operators publish runtime log-level overrides.
+// Events are LevelChange{Component, Level}; Component "*" sets the
+// WildcardComponent override, which applies to every component that has no
+// more specific override of its own.
+const LogLevelTopic eventbus.Topic = "sys.loglevel"
+
+// LevelChange is the event shape published to LogLevelTopic.
+type LevelChange struct {
+	Component string `json:"component"`
+	Level     string `json:"level"`
+}
+
+// BindToBus subscribes component to LogLevelTopic so operators can change
+// its log level at runtime without a restart. It subscribes with
+// withLast=true, so a component started after an override was published
+// picks it up immediately instead of waiting for the next change.
+//
+// The returned func stops the subscription; callers don't usually need to
+// call it since bindings normally live for the process lifetime.
+func BindToBus(bus *eventbus.Bus, component string) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, unsub := bus.Subscribe(ctx, LogLevelTopic, true)
+
+	go func() {
+		for ev := range ch {
+			change, ok := ev.(LevelChange)
+			if !ok {
+				continue
+			}
+			if change.Component == WildcardComponent {
+				SetLevel(WildcardComponent, change.Level)
+				continue
+			}
+			if change.Component != component {
+				continue
+			}
+			SetLevel(component, change.Level)
+		}
+	}()
+
+	return func() {
+		unsub()
+		cancel()
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// jsonRecord is the wire shape every JSON-emitting sink writes, one object
+// per line: zerolog-style time/level/component/msg plus whatever typed
+// fields were attached via Logger.With.
+type jsonRecord struct {
+	Time      time.Time      `json:"time"`
+	Level     string         `json:"level"`
+	Component string         `json:"component"`
+	Msg       string         `json:"msg"`
+	File      string         `json:"file,omitempty"`
+	Line      int            `json:"line,omitempty"`
+	Fields    map[string]any `json:"fields,omitempty"`
+}
+
+func toJSONRecord(e Entry) jsonRecord {
+	return jsonRecord{
+		Time:      e.Time,
+		Level:     e.Level,
+		Component: e.Prefix,
+		Msg:       e.Message,
+		File:      e.File,
+		Line:      e.Line,
+		Fields:    e.Fields,
+	}
+}
+
+// JSONSink writes one JSON object per line to w.
+type JSONSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{w: w}
+}
+
+func (s *JSONSink) Write(e Entry) {
+	data, err := json.Marshal(toJSONRecord(e))
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.Write(data)
+}
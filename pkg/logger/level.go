@@ -0,0 +1,168 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+)
+
+// severity orders levels from most to least verbose, for per-component
+// threshold comparisons.
+var severity = map[string]int{
+	"TRACE": 0,
+	"DEBUG": 1,
+	"INFO":  2,
+	"WARN":  3,
+	"ERROR": 4,
+	"FATAL": 5,
+}
+
+// Levels the /logger UI offers for a component's dropdown, most to least
+// verbose. "" clears the override (falls back to the wildcard override, or
+// the package-wide debug toggle).
+var SelectableLevels = []string{"", "TRACE", "DEBUG", "INFO", "WARN", "ERROR"}
+
+// WildcardComponent is the level key (e.g. "*=warn") applied to any
+// component without its own exact override.
+const WildcardComponent = "*"
+
+var (
+	levelMu   sync.RWMutex
+	levels    = make(map[string]string) // component (Logger.prefix) -> minimum level
+	storePath string
+)
+
+// SetLevel sets the minimum level a component logs at. level is matched
+// case-insensitively against severity's keys (e.g. "warn" and "WARN" are
+// equivalent) and stored normalized to upper case. An empty level clears
+// the override, reverting that component to the "*" override if one is
+// set, or else to the package-wide EnableDebug/IsDebug behavior.
+func SetLevel(component, level string) {
+	levelMu.Lock()
+	if level == "" {
+		delete(levels, component)
+	} else {
+		levels[component] = strings.ToUpper(level)
+	}
+	levelMu.Unlock()
+
+	if storePath != "" {
+		if err := persistLevels(storePath); err != nil {
+			// Best-effort: an override still applies for this run even if
+			// it couldn't be saved for the next one.
+			dispatch(Entry{Message: "failed to persist log levels: " + err.Error(), Level: "ERROR", Prefix: "logger"})
+		}
+	}
+}
+
+// GetLevel returns the current override for component, if any.
+func GetLevel(component string) (string, bool) {
+	levelMu.RLock()
+	defer levelMu.RUnlock()
+	level, ok := levels[component]
+	return level, ok
+}
+
+// Levels returns a snapshot of every component's current override, for an
+// HTTP status endpoint.
+func Levels() map[string]string {
+	levelMu.RLock()
+	defer levelMu.RUnlock()
+	out := make(map[string]string, len(levels))
+	for k, v := range levels {
+		out[k] = v
+	}
+	return out
+}
+
+// enabledFor reports whether a message at level should be emitted for
+// component. A component with its own exact override (e.g. "PumpCtrl") is
+// checked against that; otherwise the "*" wildcard override applies if one
+// is set (e.g. "*=warn"); otherwise it falls back to the package-wide
+// IsDebug() gate (TRACE/DEBUG suppressed unless debug is on, every other
+// level always emitted), so this is purely additive.
+func enabledFor(component, level string) bool {
+	levelMu.RLock()
+	threshold, ok := levels[component]
+	if !ok {
+		threshold, ok = levels[WildcardComponent]
+	}
+	levelMu.RUnlock()
+
+	if !ok {
+		return (level != "TRACE" && level != "DEBUG") || IsDebug()
+	}
+
+	min, known := severity[threshold]
+	if !known {
+		// An unrecognized override (e.g. a typo'd level in the config file)
+		// must not silently fall through to "emit everything" — ignore it
+		// the same as if no override were set.
+		return (level != "TRACE" && level != "DEBUG") || IsDebug()
+	}
+	return severity[level] >= min
+}
+
+// SetLevelStorePath enables persistence of level overrides to path, and
+// loads any overrides already saved there. Call once at startup, mirroring
+// how other components (dx2w history, eventbus durability) are told their
+// on-disk location via config rather than a hardcoded path.
+func SetLevelStorePath(path string) error {
+	levelMu.Lock()
+	storePath = path
+	levelMu.Unlock()
+	return loadLevels(path)
+}
+
+func loadLevels(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var loaded map[string]string
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return err
+	}
+
+	levelMu.Lock()
+	for k, v := range loaded {
+		levels[k] = strings.ToUpper(v)
+	}
+	levelMu.Unlock()
+	return nil
+}
+
+func persistLevels(path string) error {
+	levelMu.RLock()
+	data, err := json.Marshal(levels)
+	levelMu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
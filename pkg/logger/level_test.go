@@ -0,0 +1,57 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package logger
+
+import "testing"
+
+// TestSetLevelCaseInsensitive confirms a lowercase override, like the
+// LoggerConfig doc comment's own example ("warn"), actually raises the
+// threshold instead of silently falling through to the zero value and
+// enabling every level.
+func TestSetLevelCaseInsensitive(t *testing.T) {
+	t.Cleanup(func() { SetLevel("dx2w", "") })
+
+	SetLevel("dx2w", "warn")
+
+	cases := []struct {
+		level string
+		want  bool
+	}{
+		{"TRACE", false},
+		{"DEBUG", false},
+		{"INFO", false},
+		{"WARN", true},
+		{"ERROR", true},
+	}
+	for _, c := range cases {
+		if got := enabledFor("dx2w", c.level); got != c.want {
+			t.Errorf("enabledFor(%q) with override %q = %v, want %v", c.level, "warn", got, c.want)
+		}
+	}
+}
+
+// TestEnabledForUnknownOverride confirms a typo'd override (one that
+// doesn't match any severity key) is ignored rather than comparing against
+// severity's zero value, which would enable every level.
+func TestEnabledForUnknownOverride(t *testing.T) {
+	t.Cleanup(func() { SetLevel("dx2w", "") })
+
+	SetLevel("dx2w", "verbose")
+
+	if enabledFor("dx2w", "TRACE") {
+		t.Errorf("enabledFor(TRACE) with unknown override = true, want false (package default)")
+	}
+}
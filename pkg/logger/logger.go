@@ -17,39 +17,69 @@ package logger
 
 import (
 	"fmt"
-	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"runtime"
 	"sync"
+	"time"
 )
 
+// Entry is one log record handed to every registered Sink. Message is
+// already fmt.Sprintf-formatted; File/Line are only set for Error/Fatal.
+// Fields holds any contextual key/value pairs attached via Logger.With.
+type Entry struct {
+	Time    time.Time
+	Level   string
+	Prefix  string
+	Message string
+	File    string
+	Line    int
+	Fields  map[string]any
+}
+
+// Sink receives every log Entry from every Logger. Implementations must be
+// safe for concurrent use.
+type Sink interface {
+	Write(Entry)
+}
+
+// Logger is a lightweight handle around a component name and any
+// contextual fields attached via With; it carries no other state, so
+// creating one (New, or deriving one via With) is cheap.
 type Logger struct {
 	prefix string
-	logger *log.Logger
+	fields map[string]any
 }
 
 var (
-	baseLogger   *log.Logger
-	logFile      *os.File
-	once         sync.Once
+	once        sync.Once
+	defaultSink *writerSink
+	defaultMem  *memSink
+
+	sinksMu sync.RWMutex
+	sinks   []Sink
+
 	debugEnabled bool
 	debugMu      sync.RWMutex
 )
 
-// Init initializes the base logger with stdout and a log file.
-// Optionally enables debug if DEBUG env var is set.
+// Init initializes the default JSON-file+stdout sink and the in-memory
+// ring buffer backing /logger/records and /logger/stream. Optionally
+// enables debug if DEBUG env var is set. Safe to call multiple times; only
+// the first call takes effect.
 func Init(logPath string) error {
 	var err error
 	once.Do(func() {
-		logFile, err = os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		var sink *writerSink
+		sink, err = newWriterSink(logPath)
 		if err != nil {
 			return
 		}
+		defaultSink = sink
+		AddSink(sink)
 
-		mw := io.MultiWriter(os.Stdout, logFile)
-		baseLogger = log.New(mw, "", log.LstdFlags)
+		defaultMem = newMemSink(memSinkCapacity)
+		AddSink(defaultMem)
 
 		// enable debug from env at startup if wanted
 		if os.Getenv("DEBUG") != "" {
@@ -59,21 +89,39 @@ func Init(logPath string) error {
 	return err
 }
 
-// Close cleans up the log file (call on shutdown)
+// AddSink registers an additional destination for every future log Entry,
+// à la logrus hooks. A sink registered after a Logger was created still
+// sees all of that Logger's output, since dispatch is global rather than
+// per-Logger.
+func AddSink(s Sink) {
+	sinksMu.Lock()
+	sinks = append(sinks, s)
+	sinksMu.Unlock()
+}
+
+func dispatch(e Entry) {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	for _, s := range sinks {
+		s.Write(e)
+	}
+}
+
+// Close cleans up the default sink's log file (call on shutdown).
 func Close() {
-	if logFile != nil {
-		logFile.Close()
+	if defaultSink != nil {
+		defaultSink.Close()
 	}
 }
 
-// EnableDebug dynamically turns debug logging on/off
+// EnableDebug dynamically turns debug logging on/off.
 func EnableDebug(on bool) {
 	debugMu.Lock()
 	debugEnabled = on
 	debugMu.Unlock()
 }
 
-// IsDebug returns current debug state
+// IsDebug returns current debug state.
 func IsDebug() bool {
 	debugMu.RLock()
 	defer debugMu.RUnlock()
@@ -82,47 +130,72 @@ func IsDebug() bool {
 
 func New(prefix string) *Logger {
 	Init("default.log")
-	return &Logger{
-		prefix: prefix,
-		logger: log.New(baseLogger.Writer(), "", log.LstdFlags),
+	return &Logger{prefix: prefix}
+}
+
+// With returns a copy of the Logger that attaches key=val to every entry it
+// logs from here on, in addition to any fields already attached. The
+// receiver is left unmodified, so a shared base Logger can be reused to
+// derive several differently-tagged loggers (e.g. one per zwave-js node ID).
+func (l *Logger) With(key string, val any) *Logger {
+	fields := make(map[string]any, len(l.fields)+1)
+	for k, v := range l.fields {
+		fields[k] = v
 	}
+	fields[key] = val
+	return &Logger{prefix: l.prefix, fields: fields}
+}
+
+func caller(skip int) (file string, line int) {
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "", 0
+	}
+	return filepath.Base(file), line
 }
 
 func (l *Logger) Info(fmtstr string, v ...any) {
-	formatted := fmt.Sprintf(fmtstr, v...)
-	l.logger.Printf("[%s] INFO: %v", l.prefix, formatted)
+	if !enabledFor(l.prefix, "INFO") {
+		return
+	}
+	dispatch(Entry{Time: time.Now(), Level: "INFO", Prefix: l.prefix, Message: fmt.Sprintf(fmtstr, v...), Fields: l.fields})
 }
 
 func (l *Logger) Error(fmtstr string, v ...any) {
-	formatted := fmt.Sprintf(fmtstr, v...)
-	_, file, line, ok := runtime.Caller(1)
-	if ok {
-		file = filepath.Base(file)
-		l.logger.Printf("[%s] ERROR: (%s:%d) %s", l.prefix, file, line, formatted)
-	} else {
-		l.logger.Printf("[%s] ERROR: %v", l.prefix, formatted)
+	if !enabledFor(l.prefix, "ERROR") {
+		return
 	}
+	file, line := caller(2)
+	dispatch(Entry{Time: time.Now(), Level: "ERROR", Prefix: l.prefix, Message: fmt.Sprintf(fmtstr, v...), File: file, Line: line, Fields: l.fields})
 }
 
-func (l *Logger) Fatal(fmtstr string, v ...any) {
-	formatted := fmt.Sprintf(fmtstr, v...)
-	_, file, line, ok := runtime.Caller(1)
-	if ok {
-		file = filepath.Base(file)
-		l.logger.Printf("[%s] FATAL: (%s:%d) %s", l.prefix, file, line, formatted)
-	} else {
-		l.logger.Printf("[%s] FATAL: %v", l.prefix, formatted)
+func (l *Logger) Warn(fmtstr string, v ...any) {
+	if !enabledFor(l.prefix, "WARN") {
+		return
 	}
-	panic(formatted)
+	dispatch(Entry{Time: time.Now(), Level: "WARN", Prefix: l.prefix, Message: fmt.Sprintf(fmtstr, v...), Fields: l.fields})
+}
+
+func (l *Logger) Fatal(fmtstr string, v ...any) {
+	msg := fmt.Sprintf(fmtstr, v...)
+	file, line := caller(2)
+	dispatch(Entry{Time: time.Now(), Level: "FATAL", Prefix: l.prefix, Message: msg, File: file, Line: line, Fields: l.fields})
+	panic(msg)
 }
 
 func (l *Logger) Debug(fmtstr string, v ...any) {
-	debugMu.RLock()
-	enabled := debugEnabled
-	debugMu.RUnlock()
-	if !enabled {
+	if !enabledFor(l.prefix, "DEBUG") {
+		return
+	}
+	dispatch(Entry{Time: time.Now(), Level: "DEBUG", Prefix: l.prefix, Message: fmt.Sprintf(fmtstr, v...), Fields: l.fields})
+}
+
+// Trace logs at a lower priority than Debug, for the kind of per-iteration
+// detail that's noisy even with Debug on (e.g. every poll tick rather than
+// just state transitions).
+func (l *Logger) Trace(fmtstr string, v ...any) {
+	if !enabledFor(l.prefix, "TRACE") {
 		return
 	}
-	formatted := fmt.Sprintf(fmtstr, v...)
-	l.logger.Printf("[%s] DEBUG: %v", l.prefix, formatted)
+	dispatch(Entry{Time: time.Now(), Level: "TRACE", Prefix: l.prefix, Message: fmt.Sprintf(fmtstr, v...), Fields: l.fields})
 }
@@ -16,16 +16,32 @@
 package logger
 
 import (
-	"bufio"
+	"encoding/json"
 	"html/template"
-	"io"
-	"log"
 	"net/http"
-	"os"
+	"sort"
 	"strings"
 	"sync"
+
+	"github.com/gorilla/websocket"
 )
 
+// wsUpgrader upgrades /logger/stream connections. CheckOrigin mirrors the
+// thermostat websocket's: same-host or localhost browsers are allowed,
+// everything else rejected.
+var wsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return false
+		}
+		if strings.Contains(origin, "localhost") {
+			return true
+		}
+		return strings.Contains(origin, r.Host)
+	},
+}
+
 // Service implements http.Handler for debug/log control
 type Service struct {
 	mu sync.Mutex
@@ -49,11 +65,126 @@ func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 		http.Redirect(w, r, "/logger", http.StatusSeeOther)
 
+	case "/levels":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Levels())
+
+	case "/setlevel":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "bad form: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		component := r.FormValue("component")
+		if component == "" {
+			http.Error(w, "component is required", http.StatusBadRequest)
+			return
+		}
+		SetLevel(component, r.FormValue("level"))
+		http.Redirect(w, r, "/logger", http.StatusSeeOther)
+
+	case "/records":
+		w.Header().Set("Content-Type", "application/json")
+		var records []Entry
+		if defaultMem != nil {
+			records = defaultMem.Records(memSinkCapacity)
+		}
+		json.NewEncoder(w).Encode(records)
+
+	case "/stream":
+		s.streamWS(w, r)
+
 	default:
 		s.renderPage(w, r)
 	}
 }
 
+// streamWS upgrades to a websocket and pushes the last 250 buffered log
+// lines, then every new Entry live as it's written, each as a JSON frame
+// carrying level/tag metadata so a future UI can filter by it. It runs
+// until the client disconnects or a write fails.
+func (s *Service) streamWS(w http.ResponseWriter, r *http.Request) {
+	if defaultMem == nil {
+		http.Error(w, "log streaming unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	ws, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	// gorilla/websocket requires a read loop to process control frames
+	// (pings/close) even on a write-only connection; its only job here is
+	// to notice when the client goes away.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, e := range defaultMem.Records(250) {
+		if err := ws.WriteJSON(toJSONRecord(e)); err != nil {
+			return
+		}
+	}
+
+	ch, unsub := defaultMem.subscribe()
+	defer unsub()
+
+	for {
+		select {
+		case <-done:
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := ws.WriteJSON(toJSONRecord(e)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// levelRow is one row of the per-tag levels table: component plus its
+// current override, if any ("" means "no override, falls back to the
+// wildcard override or the package-wide debug toggle").
+type levelRow struct {
+	Component string
+	Level     string
+}
+
+// levelRows returns every component with an explicit override, sorted by
+// name, with the "*" wildcard row always listed first regardless of
+// whether it's currently set.
+func levelRows() []levelRow {
+	overrides := Levels()
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		if name == WildcardComponent {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := []levelRow{{Component: WildcardComponent, Level: overrides[WildcardComponent]}}
+	for _, name := range names {
+		rows = append(rows, levelRow{Component: name, Level: overrides[name]})
+	}
+	return rows
+}
+
 func (s *Service) renderPage(w http.ResponseWriter, _ *http.Request) {
 	logs, _ := s.tail(250) // last 250 lines
 
@@ -86,76 +217,92 @@ func (s *Service) renderPage(w http.ResponseWriter, _ *http.Request) {
   <form method="POST" action="/logger/clear" style="display:inline;">
     <button class="btn btn-danger" type="submit">Clear Log</button>
   </form>
-  <h2>Last 250 log lines</h2>
-  <pre class="log">{{.Log}}</pre>
+  <a class="btn" href="/logger/records">Last Records (JSON)</a>
+
+  <h2>Log levels</h2>
+  <table border="1" cellpadding="6" cellspacing="0" style="border-collapse:collapse; background:white;">
+    <tr><th>Tag</th><th>Level</th><th></th></tr>
+    {{range .LevelRows}}
+    <tr>
+      <td>{{if eq .Component "*"}}* (default){{else}}{{.Component}}{{end}}</td>
+      <td>
+        <form method="POST" action="/logger/setlevel" style="display:flex; gap:0.5em; align-items:center;">
+          <input type="hidden" name="component" value="{{.Component}}">
+          <select name="level">
+            {{$cur := .Level}}
+            {{range $.SelectableLevels}}
+            <option value="{{.}}" {{if eq . $cur}}selected{{end}}>{{if eq . ""}}(none){{else}}{{.}}{{end}}</option>
+            {{end}}
+          </select>
+          <button class="btn" type="submit">Set</button>
+        </form>
+      </td>
+      <td></td>
+    </tr>
+    {{end}}
+    <tr>
+      <td colspan="3">
+        <form method="POST" action="/logger/setlevel" style="display:flex; gap:0.5em; align-items:center;">
+          <input type="text" name="component" placeholder="tag name">
+          <select name="level">
+            {{range .SelectableLevels}}
+            <option value="{{.}}">{{if eq . ""}}(none){{else}}{{.}}{{end}}</option>
+            {{end}}
+          </select>
+          <button class="btn" type="submit">Add override</button>
+        </form>
+      </td>
+    </tr>
+  </table>
+
+  <h2>Log (live)</h2>
+  <pre class="log" id="log">{{.Log}}</pre>
+
+  <script>
+  (function() {
+    var pre = document.getElementById('log');
+    var proto = location.protocol === 'https:' ? 'wss://' : 'ws://';
+    var sock = new WebSocket(proto + location.host + '/logger/stream');
+    var cleared = false;
+
+    sock.onmessage = function(ev) {
+      if (!cleared) {
+        pre.textContent = '';
+        cleared = true;
+      }
+      var rec = JSON.parse(ev.data);
+      pre.textContent += rec.time + ' [' + rec.component + '] ' + rec.level + ': ' + rec.msg + '\n';
+      pre.scrollTop = pre.scrollHeight;
+    };
+  })();
+  </script>
 </body>
 </html>
 `
 	t := template.Must(template.New("page").Parse(tpl))
 	_ = t.Execute(w, map[string]any{
-		"Debug": IsDebug(),
-		"Log":   logs,
+		"Debug":           IsDebug(),
+		"Log":             logs,
+		"LevelRows":       levelRows(),
+		"SelectableLevels": SelectableLevels,
 	})
 }
 
-// clearLog truncates and reopens the log file, rebuilding baseLogger
+// clearLog truncates and reopens the default sink's log file.
 func (s *Service) clearLog() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if logFile == nil {
+	if defaultSink == nil {
 		return nil
 	}
-
-	// Close old file
-	name := logFile.Name()
-	logFile.Close()
-
-	// Truncate file
-	f, err := os.OpenFile(name, os.O_TRUNC|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
-	}
-	f.Close()
-
-	// Reopen and replace globals
-	newf, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return err
-	}
-	logFile = newf
-
-	// rebuild baseLogger with stdout + new file
-	mw := io.MultiWriter(os.Stdout, logFile)
-	baseLogger = newBaseLogger(mw)
-
-	return nil
+	return defaultSink.Clear()
 }
 
-// tail reads last n lines of the log file
+// tail reads the last n lines of the default sink's log file.
 func (s *Service) tail(n int) (string, error) {
-	if logFile == nil {
+	if defaultSink == nil {
 		return "", nil
 	}
-	f, err := os.Open(logFile.Name())
-	if err != nil {
-		return "", err
-	}
-	defer f.Close()
-
-	var lines []string
-	sc := bufio.NewScanner(f)
-	for sc.Scan() {
-		lines = append(lines, sc.Text())
-	}
-	if len(lines) > n {
-		lines = lines[len(lines)-n:]
-	}
-	// Join with newlines so each appears properly
-	return strings.Join(lines, "\n"), sc.Err()
-}
-
-// helper to create baseLogger (keeps same flags)
-func newBaseLogger(w io.Writer) *log.Logger {
-	return log.New(w, "", log.LstdFlags)
+	return defaultSink.Tail(n)
 }
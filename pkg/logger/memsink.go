@@ -0,0 +1,86 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package logger
+
+import "sync"
+
+// memSinkCapacity bounds how many recent Entries the default in-memory
+// ring buffer retains for the /logger/records endpoint.
+const memSinkCapacity = 1000
+
+// memSink keeps the last N entries in memory and fans new ones out live to
+// any subscribed channel, backing the /logger/records and /logger/stream
+// endpoints.
+type memSink struct {
+	mu   sync.Mutex
+	buf  []Entry
+	cap  int
+	subs map[chan Entry]bool
+}
+
+func newMemSink(capacity int) *memSink {
+	return &memSink{cap: capacity, subs: make(map[chan Entry]bool)}
+}
+
+func (s *memSink) Write(e Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf = append(s.buf, e)
+	if len(s.buf) > s.cap {
+		s.buf = s.buf[len(s.buf)-s.cap:]
+	}
+	for ch := range s.subs {
+		select {
+		case ch <- e:
+		default:
+			// slow subscriber: drop rather than block the logger hot path
+		}
+	}
+}
+
+// Records returns a copy of the last n retained entries, oldest first. n <=
+// 0 returns everything retained.
+func (s *memSink) Records(n int) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf := s.buf
+	if n > 0 && len(buf) > n {
+		buf = buf[len(buf)-n:]
+	}
+	out := make([]Entry, len(buf))
+	copy(out, buf)
+	return out
+}
+
+// subscribe registers ch to receive every future Entry live. The returned
+// func unregisters and closes ch; call it exactly once.
+func (s *memSink) subscribe() (chan Entry, func()) {
+	ch := make(chan Entry, 16)
+	s.mu.Lock()
+	s.subs[ch] = true
+	s.mu.Unlock()
+
+	return ch, func() {
+		s.mu.Lock()
+		if _, ok := s.subs[ch]; ok {
+			delete(s.subs, ch)
+			close(ch)
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// formatLine renders an Entry the same way across the rotating file, the
+// stdout tee, and any other plain-text sink.
+func formatLine(e Entry) string {
+	ts := e.Time.Format("2006-01-02 15:04:05")
+	if e.File != "" {
+		return fmt.Sprintf("%s [%s] %s: (%s:%d) %s%s\n", ts, e.Prefix, e.Level, e.File, e.Line, e.Message, formatFields(e.Fields))
+	}
+	return fmt.Sprintf("%s [%s] %s: %s%s\n", ts, e.Prefix, e.Level, e.Message, formatFields(e.Fields))
+}
+
+// formatFields renders an Entry's contextual fields as " key=val key2=val2",
+// sorted by key for stable output, or "" if there are none.
+func formatFields(fields map[string]any) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, fields[k])
+	}
+	return b.String()
+}
+
+// RotatingFileSink writes log lines to a file, rotating to a gzip-compressed
+// segment once the file exceeds maxBytes or has been open longer than
+// maxAge (0 disables the age-based trigger), the same size+age/gzip pattern
+// HistoryService.saveToDisk uses for history snapshots.
+type RotatingFileSink struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	maxAge   time.Duration
+	file     *os.File
+	curBytes int64
+	openedAt time.Time
+}
+
+func NewRotatingFileSink(path string, maxBytes int64, maxAge time.Duration) (*RotatingFileSink, error) {
+	s := &RotatingFileSink{path: path, maxBytes: maxBytes, maxAge: maxAge}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *RotatingFileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	s.curBytes = 0
+	if info, err := file.Stat(); err == nil {
+		s.curBytes = info.Size()
+	}
+	s.file = file
+	s.openedAt = time.Now()
+	return nil
+}
+
+func (s *RotatingFileSink) Write(e Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.curBytes >= s.maxBytes || (s.maxAge > 0 && time.Since(s.openedAt) >= s.maxAge) {
+		s.rotate()
+	}
+
+	line := formatLine(e)
+	n, err := s.file.WriteString(line)
+	if err == nil {
+		s.curBytes += int64(n)
+	} else {
+		fmt.Fprintf(os.Stderr, "logger: failed to write %s: %v\n", s.path, err)
+	}
+}
+
+func (s *RotatingFileSink) rotate() {
+	s.file.Close()
+
+	gzPath := fmt.Sprintf("%s.%s.gz", s.path, time.Now().Format("20060102T150405"))
+	if err := gzipAndRemove(s.path, gzPath); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: failed to rotate %s: %v\n", s.path, err)
+	}
+
+	if err := s.open(); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: failed to reopen %s after rotation: %v\n", s.path, err)
+	}
+}
+
+func (s *RotatingFileSink) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.file.Close()
+}
+
+func gzipAndRemove(srcPath, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, bufio.NewReader(src)); err != nil {
+		gz.Close()
+		dst.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(srcPath)
+}
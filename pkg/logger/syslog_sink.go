@@ -0,0 +1,102 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const syslogFacilityUser = 1 // RFC5424 facility 1 ("user-level messages")
+
+// SyslogSink ships log entries to a remote syslog collector as RFC5424
+// messages over network ("udp", "tcp", or "unix").
+type SyslogSink struct {
+	mu       sync.Mutex
+	network  string
+	raddr    string
+	appName  string
+	hostname string
+	conn     net.Conn
+}
+
+// NewSyslogSink dials network/raddr (e.g. "udp", "syslog.example.com:514")
+// and returns a sink that tags every message with appName.
+func NewSyslogSink(network, raddr, appName string) (*SyslogSink, error) {
+	conn, err := net.Dial(network, raddr)
+	if err != nil {
+		return nil, fmt.Errorf("dial syslog %s %s: %w", network, raddr, err)
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return &SyslogSink{
+		network:  network,
+		raddr:    raddr,
+		appName:  appName,
+		hostname: hostname,
+		conn:     conn,
+	}, nil
+}
+
+func syslogSeverity(level string) int {
+	switch level {
+	case "FATAL":
+		return 2 // critical
+	case "ERROR":
+		return 3 // error
+	case "WARN":
+		return 4 // warning
+	case "DEBUG", "TRACE":
+		return 7 // debug
+	default:
+		return 6 // informational
+	}
+}
+
+func (s *SyslogSink) Write(e Entry) {
+	msg := e.Message
+	if e.File != "" {
+		msg = fmt.Sprintf("(%s:%d) %s", e.File, e.Line, e.Message)
+	}
+
+	pri := syslogFacilityUser*8 + syslogSeverity(e.Level)
+	line := fmt.Sprintf("<%d>1 %s %s %s %d - [%s] %s\n",
+		pri, e.Time.UTC().Format(time.RFC3339), s.hostname, s.appName, os.Getpid(), e.Prefix, msg)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.conn.Write([]byte(line)); err != nil {
+		// best-effort single reconnect attempt; drop the message if it fails too
+		conn, dialErr := net.Dial(s.network, s.raddr)
+		if dialErr != nil {
+			return
+		}
+		s.conn.Close()
+		s.conn = conn
+		s.conn.Write([]byte(line))
+	}
+}
+
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn.Close()
+}
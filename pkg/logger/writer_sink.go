@@ -0,0 +1,116 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+)
+
+// writerSink is the default sink: a JSON-lines log file (machine-parseable,
+// and what Tail reads back for the /logger page), plus a
+// human-formatted tee to stdout when stdout is attached to a terminal.
+type writerSink struct {
+	mu    sync.Mutex
+	file  *os.File
+	isTTY bool
+}
+
+func newWriterSink(path string) (*writerSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &writerSink{
+		file:  file,
+		isTTY: isTerminal(os.Stdout),
+	}, nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func (s *writerSink) Write(e Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if data, err := json.Marshal(toJSONRecord(e)); err == nil {
+		data = append(data, '\n')
+		s.file.Write(data)
+	}
+	if s.isTTY {
+		os.Stdout.WriteString(formatLine(e))
+	}
+}
+
+func (s *writerSink) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.file.Close()
+}
+
+// Clear truncates the log file and reopens it.
+func (s *writerSink) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := s.file.Name()
+	s.file.Close()
+
+	f, err := os.OpenFile(name, os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	f.Close()
+
+	newFile, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	s.file = newFile
+	return nil
+}
+
+// Tail returns the last n lines of the log file.
+func (s *writerSink) Tail(n int) (string, error) {
+	s.mu.Lock()
+	name := s.file.Name()
+	s.mu.Unlock()
+
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var lines []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n"), sc.Err()
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package metrics runs a standalone Prometheus scrape endpoint for the
+// process-wide sysmon.Registry. rootserv already serves the same registry
+// under "/metrics", but that sits behind rootserv's main listener, auth
+// middleware, and index page; this gives scrapers a plain, unauthenticated
+// listener on its own port, which is what most Prometheus deployments
+// expect and is simpler to firewall off from the public-facing app port.
+package metrics
+
+import (
+	"burlo/v2/internal/config"
+	"burlo/v2/pkg/logger"
+	"burlo/v2/pkg/sysmon"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Service serves reg on its own HTTP listener at "/metrics".
+type Service struct {
+	addr string
+	reg  *sysmon.Registry
+	log  *logger.Logger
+}
+
+// New builds a Service from conf.Metrics and reg. Pass the same registry
+// given to rootserv.New and the other subsystems so every collector they
+// register shows up here too.
+func New(conf config.MetricsConfig, reg *sysmon.Registry) *Service {
+	return &Service{
+		addr: conf.HTTPAddr,
+		reg:  reg,
+		log:  logger.New("Metrics"),
+	}
+}
+
+func (s *Service) Run(ctx context.Context) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(s.reg.Registry, promhttp.HandlerOpts{}))
+	srv := &http.Server{Addr: s.addr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	s.log.Info("serving /metrics on %s", s.addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		s.log.Error("metrics server stopped: %v", err)
+	}
+}
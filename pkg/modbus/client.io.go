@@ -21,10 +21,23 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"time"
 )
 
+// observeRegisterIO records latency and, on failure, increments the
+// register-level error counter for a ReadValue/WriteValue call. No-op if
+// the client wasn't built with a metrics registry.
+func (c *Client) observeRegisterIO(name, op string, start time.Time, err error) {
+	if c.registerLatency != nil {
+		c.registerLatency.WithLabelValues(name, op).Observe(time.Since(start).Seconds())
+	}
+	if err != nil && c.registerErrorsTotal != nil {
+		c.registerErrorsTotal.WithLabelValues(name, op).Inc()
+	}
+}
+
 // ReadTyped reads a register value and converts it into the requested type T.
-// Supported T: float32, float64, int16, uint16, int, bool
+// Supported T: float32, float64, int16, uint16, int32, uint32, int, uint, bool
 func ReadTyped[T any](c *Client, name string) (T, error) {
 	var zero T
 
@@ -43,11 +56,34 @@ func ReadTyped[T any](c *Client, name string) (T, error) {
 		return any(f32).(T), nil
 
 	case float64:
-		f32, ok := val.(float32)
-		if !ok {
+		switch v := val.(type) {
+		case float32:
+			return any(float64(v)).(T), nil
+		case float64:
+			return any(v).(T), nil
+		default:
 			return zero, fmt.Errorf("cannot convert %T to float64", val)
 		}
-		return any(float64(f32)).(T), nil
+
+	case int32:
+		switch v := val.(type) {
+		case float32:
+			return any(int32(math.Round(float64(v)))).(T), nil
+		case int32:
+			return any(v).(T), nil
+		default:
+			return zero, fmt.Errorf("cannot convert %T to int32", val)
+		}
+
+	case uint32:
+		switch v := val.(type) {
+		case float32:
+			return any(uint32(math.Round(float64(v)))).(T), nil
+		case uint32:
+			return any(v).(T), nil
+		default:
+			return zero, fmt.Errorf("cannot convert %T to uint32", val)
+		}
 
 	case int16:
 		switch v := val.(type) {
@@ -103,15 +139,28 @@ func ReadTyped[T any](c *Client, name string) (T, error) {
 
 // ReadValue reads a register by name and returns its decoded value as `any`.
 // Supported return types:
-//   - float32 (for float32 or scaled int16/uint16 registers)
+//   - float32 (for float32, or scaled int16/uint16/int32/uint32 registers)
+//   - float64 (for float64 registers without scaling)
 //   - int16   (for int16 registers without scaling)
 //   - uint16  (for uint16 registers without scaling)
+//   - int32   (for int32 registers without scaling)
+//   - uint32  (for uint32 registers without scaling)
 //   - bool    (for bool registers)
 func (c *Client) ReadValue(name string) (any, error) {
+	start := time.Now()
+	val, err := c.readValue(name)
+	c.observeRegisterIO(name, "read", start, err)
+	return val, err
+}
+
+func (c *Client) readValue(name string) (any, error) {
 	regDef, ok := c.config.Registers[name]
 	if !ok {
 		return nil, fmt.Errorf("register %q not configured", name)
 	}
+	if err := validOrder(regDef.ByteOrder, regDef.WordOrder); err != nil {
+		return nil, fmt.Errorf("register %q: %w", name, err)
+	}
 
 	var valf64 float64
 	var raw []byte
@@ -127,6 +176,7 @@ func (c *Client) ReadValue(name string) (any, error) {
 	if len(raw) < int(nregisters*2) {
 		return nil, fmt.Errorf("register %q returned insufficient data", name)
 	}
+	raw = reorderRegisters(raw, regDef.ByteOrder, regDef.WordOrder)
 
 	switch regDef.DataType {
 	case "float32":
@@ -135,6 +185,12 @@ func (c *Client) ReadValue(name string) (any, error) {
 			return float32(valf64), nil
 		}
 
+	case "float64":
+		valf64 = bytesToFloat64(raw)
+		if regDef.Scale == 0 {
+			return valf64, nil
+		}
+
 	case "int16":
 		valf64 = float64(bytesToInt16(raw))
 		if regDef.Scale == 0 {
@@ -147,6 +203,18 @@ func (c *Client) ReadValue(name string) (any, error) {
 			return uint16(valf64), nil
 		}
 
+	case "int32":
+		valf64 = float64(bytesToInt32(raw))
+		if regDef.Scale == 0 {
+			return int32(valf64), nil
+		}
+
+	case "uint32":
+		valf64 = float64(bytesToUint32(raw))
+		if regDef.Scale == 0 {
+			return uint32(valf64), nil
+		}
+
 	case "bool", "binary":
 		return bytesToUint16(raw) != 0, nil
 
@@ -165,10 +233,20 @@ func (c *Client) ReadValue(name string) (any, error) {
 //   - int     (for int16/uint16 registers)
 //   - bool    (for bool registers)
 func (c *Client) WriteValue(name string, value any) error {
+	start := time.Now()
+	err := c.writeValue(name, value)
+	c.observeRegisterIO(name, "write", start, err)
+	return err
+}
+
+func (c *Client) writeValue(name string, value any) error {
 	regDef, ok := c.config.Registers[name]
 	if !ok {
 		return fmt.Errorf("register %q not configured", name)
 	}
+	if err := validOrder(regDef.ByteOrder, regDef.WordOrder); err != nil {
+		return fmt.Errorf("register %q: %w", name, err)
+	}
 
 	c.log.Info("WriteRegister '%s' <- %v", name, value)
 
@@ -209,6 +287,26 @@ func (c *Client) WriteValue(name string, value any) error {
 		raw = uint16ToBytes(uint16(ival))
 		nregisters = 1
 
+	case "float64":
+		raw = float64ToBytes(valf64)
+		nregisters = 4
+
+	case "int32":
+		ival := int64(math.Round(valf64))
+		if ival < math.MinInt32 || ival > math.MaxInt32 {
+			return fmt.Errorf("value %v out of int32 range for register %q", valf64, name)
+		}
+		raw = int32ToBytes(int32(ival))
+		nregisters = 2
+
+	case "uint32":
+		ival := uint64(math.Round(valf64))
+		if ival > math.MaxUint32 {
+			return fmt.Errorf("value %v out of uint32 range for register %q", valf64, name)
+		}
+		raw = uint32ToBytes(uint32(ival))
+		nregisters = 2
+
 	case "bool":
 		if valf64 != 0 {
 			raw = uint16ToBytes(math.MaxUint16)
@@ -221,8 +319,8 @@ func (c *Client) WriteValue(name string, value any) error {
 		return fmt.Errorf("unsupported data type %q for register %q", regDef.DataType, name)
 	}
 
-	_, err = c.client.WriteMultipleRegisters(c.ctx, regDef.Address, nregisters, raw)
-	if err != nil {
+	raw = reorderRegisters(raw, regDef.ByteOrder, regDef.WordOrder)
+	if err := c.WriteMultipleRegisters(c.ctx, regDef.Address, nregisters, raw); err != nil {
 		return fmt.Errorf("failed to write register %q: %w", name, err)
 	}
 	return nil
@@ -230,18 +328,67 @@ func (c *Client) WriteValue(name string, value any) error {
 
 func (c *Client) registerCountFromDataType(dt string) uint16 {
 	switch dt {
-	case "uint16", "int16":
+	case "uint16", "int16", "bool":
 		return 1
-	case "float32":
+	case "float32", "int32", "uint32":
 		return 2
-	case "bool":
-		return 1
+	case "float64":
+		return 4
 	default:
 		c.log.Fatal("fatal: unhandled registerCountFromDataType: %q", dt)
 		return 0
 	}
 }
 
+// validOrder rejects any ByteOrder/WordOrder value other than the four
+// combinations RegisterDef documents; "" means "use the default" for both.
+func validOrder(byteOrder, wordOrder string) error {
+	switch byteOrder {
+	case "", "AB", "BA":
+	default:
+		return fmt.Errorf("invalid byte_order %q (want \"AB\" or \"BA\")", byteOrder)
+	}
+	switch wordOrder {
+	case "", "ABCD", "CDAB":
+	default:
+		return fmt.Errorf("invalid word_order %q (want \"ABCD\" or \"CDAB\")", wordOrder)
+	}
+	return nil
+}
+
+// applyByteOrder swaps the two bytes within every 16-bit register in raw
+// when byteOrder is "BA"; "" and "AB" (network order) leave raw unchanged.
+func applyByteOrder(raw []byte, byteOrder string) []byte {
+	if byteOrder != "BA" {
+		return raw
+	}
+	out := make([]byte, len(raw))
+	for i := 0; i+1 < len(raw); i += 2 {
+		out[i], out[i+1] = raw[i+1], raw[i]
+	}
+	return out
+}
+
+// applyWordOrder swaps the high and low registers of a 4-byte value when
+// wordOrder is "CDAB"; "" and "ABCD" (high word first) leave raw unchanged.
+// No-op for any length other than 4, since WordOrder only applies to
+// 32-bit data types.
+func applyWordOrder(raw []byte, wordOrder string) []byte {
+	if wordOrder != "CDAB" || len(raw) != 4 {
+		return raw
+	}
+	return []byte{raw[2], raw[3], raw[0], raw[1]}
+}
+
+// reorderRegisters converts raw between a register's on-the-wire byte/word
+// order and the canonical big-endian, high-word-first order the
+// bytesToX/xToBytes helpers below expect. Both applyByteOrder and
+// applyWordOrder are swaps of disjoint byte pairs, so reorderRegisters is
+// its own inverse: the same call decodes a read and encodes a write.
+func reorderRegisters(raw []byte, byteOrder, wordOrder string) []byte {
+	return applyByteOrder(applyWordOrder(raw, wordOrder), byteOrder)
+}
+
 func bytesToUint16(b []byte) uint16 {
 	return binary.BigEndian.Uint16(b)
 }
@@ -256,6 +403,36 @@ func uint16ToBytes(v uint16) []byte {
 	return buf
 }
 
+func bytesToInt32(b []byte) int32 {
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func bytesToUint32(b []byte) uint32 {
+	return binary.BigEndian.Uint32(b)
+}
+
+func bytesToFloat64(b []byte) float64 {
+	return math.Float64frombits(binary.BigEndian.Uint64(b))
+}
+
+func int32ToBytes(v int32) []byte {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, uint32(v))
+	return buf
+}
+
+func uint32ToBytes(v uint32) []byte {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, v)
+	return buf
+}
+
+func float64ToBytes(f float64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, math.Float64bits(f))
+	return buf
+}
+
 func bytesToFloat32(data []byte) float32 {
 	var floatVal float32
 	buf := bytes.NewReader(data)
@@ -0,0 +1,164 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package modbus
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestReorderRegistersWireLayout checks that each byte_order/word_order
+// combination produces the standard ABCD/BADC/CDAB/DCBA wire layout its
+// name promises.
+func TestReorderRegistersWireLayout(t *testing.T) {
+	abcd := []byte{0x12, 0x34, 0x56, 0x78}
+
+	cases := []struct {
+		name      string
+		byteOrder string
+		wordOrder string
+		want      []byte
+	}{
+		{"ABCD", "AB", "ABCD", []byte{0x12, 0x34, 0x56, 0x78}},
+		{"BADC", "BA", "ABCD", []byte{0x34, 0x12, 0x78, 0x56}},
+		{"CDAB", "AB", "CDAB", []byte{0x56, 0x78, 0x12, 0x34}},
+		{"DCBA", "BA", "CDAB", []byte{0x78, 0x56, 0x34, 0x12}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := reorderRegisters(abcd, tc.byteOrder, tc.wordOrder)
+			if !bytes.Equal(got, tc.want) {
+				t.Errorf("reorderRegisters(%x, %q, %q) = %x, want %x",
+					abcd, tc.byteOrder, tc.wordOrder, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestReorderRegistersRoundTrip confirms reorderRegisters is its own
+// inverse: wire bytes produced for a given order decode back to the
+// original canonical-order bytes under the same order.
+func TestReorderRegistersRoundTrip(t *testing.T) {
+	orders := []struct{ byteOrder, wordOrder string }{
+		{"AB", "ABCD"},
+		{"BA", "ABCD"},
+		{"AB", "CDAB"},
+		{"BA", "CDAB"},
+	}
+
+	for _, o := range orders {
+		canonical := []byte{0xDE, 0xAD, 0xBE, 0xEF}
+		wire := reorderRegisters(canonical, o.byteOrder, o.wordOrder)
+		back := reorderRegisters(wire, o.byteOrder, o.wordOrder)
+		if !bytes.Equal(back, canonical) {
+			t.Errorf("byte_order=%q word_order=%q: round trip got %x, want %x",
+				o.byteOrder, o.wordOrder, back, canonical)
+		}
+	}
+}
+
+// TestRegisterValueRoundTrip encodes a value to wire bytes for every
+// data_type + byte_order (+ word_order, for 32-bit types) permutation and
+// confirms decoding those wire bytes recovers the original value.
+func TestRegisterValueRoundTrip(t *testing.T) {
+	t.Run("int16", func(t *testing.T) {
+		want := int16(-1234)
+		for _, byteOrder := range []string{"AB", "BA"} {
+			wire := reorderRegisters(uint16ToBytes(uint16(want)), byteOrder, "")
+			got := bytesToInt16(reorderRegisters(wire, byteOrder, ""))
+			if got != want {
+				t.Errorf("byte_order=%q: got %d, want %d", byteOrder, got, want)
+			}
+		}
+	})
+
+	t.Run("uint16", func(t *testing.T) {
+		for _, byteOrder := range []string{"AB", "BA"} {
+			wire := reorderRegisters(uint16ToBytes(54321), byteOrder, "")
+			got := bytesToUint16(reorderRegisters(wire, byteOrder, ""))
+			if got != 54321 {
+				t.Errorf("byte_order=%q: got %d, want 54321", byteOrder, got)
+			}
+		}
+	})
+
+	t.Run("int32", func(t *testing.T) {
+		for _, byteOrder := range []string{"AB", "BA"} {
+			for _, wordOrder := range []string{"ABCD", "CDAB"} {
+				wire := reorderRegisters(int32ToBytes(-123456789), byteOrder, wordOrder)
+				got := bytesToInt32(reorderRegisters(wire, byteOrder, wordOrder))
+				if got != -123456789 {
+					t.Errorf("byte_order=%q word_order=%q: got %d, want -123456789", byteOrder, wordOrder, got)
+				}
+			}
+		}
+	})
+
+	t.Run("uint32", func(t *testing.T) {
+		for _, byteOrder := range []string{"AB", "BA"} {
+			for _, wordOrder := range []string{"ABCD", "CDAB"} {
+				wire := reorderRegisters(uint32ToBytes(3000000000), byteOrder, wordOrder)
+				got := bytesToUint32(reorderRegisters(wire, byteOrder, wordOrder))
+				if got != 3000000000 {
+					t.Errorf("byte_order=%q word_order=%q: got %d, want 3000000000", byteOrder, wordOrder, got)
+				}
+			}
+		}
+	})
+
+	t.Run("float32", func(t *testing.T) {
+		for _, byteOrder := range []string{"AB", "BA"} {
+			for _, wordOrder := range []string{"ABCD", "CDAB"} {
+				wire := reorderRegisters(float32ToBytes(21.5), byteOrder, wordOrder)
+				got := bytesToFloat32(reorderRegisters(wire, byteOrder, wordOrder))
+				if got != 21.5 {
+					t.Errorf("byte_order=%q word_order=%q: got %v, want 21.5", byteOrder, wordOrder, got)
+				}
+			}
+		}
+	})
+
+	t.Run("float64", func(t *testing.T) {
+		for _, byteOrder := range []string{"AB", "BA"} {
+			wire := reorderRegisters(float64ToBytes(-98765.4321), byteOrder, "")
+			got := bytesToFloat64(reorderRegisters(wire, byteOrder, ""))
+			if got != -98765.4321 {
+				t.Errorf("byte_order=%q: got %v, want -98765.4321", byteOrder, got)
+			}
+		}
+	})
+}
+
+func TestValidOrder(t *testing.T) {
+	valid := []struct{ byteOrder, wordOrder string }{
+		{"", ""}, {"AB", ""}, {"BA", "ABCD"}, {"AB", "CDAB"}, {"BA", "CDAB"},
+	}
+	for _, v := range valid {
+		if err := validOrder(v.byteOrder, v.wordOrder); err != nil {
+			t.Errorf("validOrder(%q, %q) = %v, want nil", v.byteOrder, v.wordOrder, err)
+		}
+	}
+
+	invalid := []struct{ byteOrder, wordOrder string }{
+		{"XY", ""}, {"", "BADC"}, {"ABCD", "ABCD"},
+	}
+	for _, v := range invalid {
+		if err := validOrder(v.byteOrder, v.wordOrder); err == nil {
+			t.Errorf("validOrder(%q, %q) = nil, want error", v.byteOrder, v.wordOrder)
+		}
+	}
+}
@@ -17,6 +17,7 @@ package modbus
 
 import (
 	"burlo/v2/pkg/logger"
+	"burlo/v2/pkg/sysmon"
 	"context"
 	"errors"
 	"fmt"
@@ -25,20 +26,33 @@ import (
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	wrapper "github.com/grid-x/modbus"
 )
 
 type Client struct {
-	mu      sync.Mutex
-	handler *wrapper.TCPClientHandler
-	client  wrapper.Client
-	config  *Config
-	log     *logger.Logger
-	ctx     context.Context
+	mu        sync.Mutex
+	transport Transport
+	client    wrapper.Client
+	config    *Config
+	log       *logger.Logger
+	ctx       context.Context
+
+	reconnectsTotal prometheus.Counter
+	errorsTotal     *prometheus.CounterVec
+
+	// registerLatency/registerErrorsTotal are the named-register-level
+	// counterparts of errorsTotal, keyed by register name rather than just
+	// op, recorded by ReadValue/WriteValue in client.io.go.
+	registerLatency     *prometheus.HistogramVec
+	registerErrorsTotal *prometheus.CounterVec
 }
 
 // NewClient creates and connects a Modbus TCP client with sane defaults.
-func NewClient(ctx context.Context, config *Config) *Client {
+// If reg is non-nil, burlo_modbus_reconnects_total, burlo_modbus_errors_total{op},
+// burlo_modbus_register_io_seconds{register,op} and
+// burlo_modbus_register_errors_total{register,op} are registered on it.
+func NewClient(ctx context.Context, config *Config, reg *sysmon.Registry) *Client {
 	log := logger.New("ModbusConn")
 
 	c := &Client{
@@ -46,6 +60,28 @@ func NewClient(ctx context.Context, config *Config) *Client {
 		log:    log,
 		ctx:    ctx,
 	}
+
+	if reg != nil {
+		c.reconnectsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "burlo_modbus_reconnects_total",
+			Help: "Total number of Modbus (re)connect attempts that succeeded.",
+		})
+		c.errorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "burlo_modbus_errors_total",
+			Help: "Total Modbus read/write errors, labeled by operation.",
+		}, []string{"op"})
+		c.registerLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "burlo_modbus_register_io_seconds",
+			Help:    "Named register read/write latency, labeled by register and operation.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"register", "op"})
+		c.registerErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "burlo_modbus_register_errors_total",
+			Help: "Total failed named register reads/writes, labeled by register and operation.",
+		}, []string{"register", "op"})
+		reg.MustRegister(c.reconnectsTotal, c.errorsTotal, c.registerLatency, c.registerErrorsTotal)
+	}
+
 	if err := c.connectWithRetry(); err != nil {
 		log.Fatal("failed to connect to modbus device: %v", err)
 	}
@@ -78,36 +114,51 @@ func (c *Client) connect() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.handler != nil {
-		_ = c.handler.Close()
+	if c.transport != nil {
+		_ = c.transport.Close()
 	}
 
-	url := fmt.Sprintf("%s:%d", c.config.Modbus.Host, c.config.Modbus.Port)
-	handler := wrapper.NewTCPClientHandler(url)
-	handler.SlaveID = c.config.Modbus.SlaveID
-	handler.Timeout = time.Second * time.Duration(c.config.Modbus.Timeout)
-	handler.ProtocolRecoveryTimeout = 250 * time.Millisecond
-	handler.LinkRecoveryTimeout = 5 * time.Second
+	transport, client, err := newTransport(c.config.Modbus)
+	if err != nil {
+		return err
+	}
 
-	c.log.Info("Connecting to %s...", url)
-	if err := handler.Connect(c.ctx); err != nil {
+	c.log.Info("Connecting (%s)...", describeTransport(c.config.Modbus))
+	if err := transport.Connect(c.ctx); err != nil {
 		return fmt.Errorf("modbus connect failed: %w", err)
 	}
 
-	c.handler = handler
-	c.client = wrapper.NewClient(handler)
-	c.log.Info("Connected to %s", url)
+	c.transport = transport
+	c.client = client
+	c.log.Info("Connected (%s)", describeTransport(c.config.Modbus))
+	if c.reconnectsTotal != nil {
+		c.reconnectsTotal.Inc()
+	}
 	return nil
 }
 
+func describeTransport(cfg ModbusConfig) string {
+	switch cfg.Transport {
+	case "rtu":
+		return fmt.Sprintf("rtu %s", cfg.SerialPort)
+	case "rtuovertcp":
+		return fmt.Sprintf("rtuovertcp %s:%d", cfg.Host, cfg.Port)
+	default:
+		return fmt.Sprintf("tcp %s:%d", cfg.Host, cfg.Port)
+	}
+}
+
 // retry wraps Modbus operations and reconnects automatically if needed.
-func (c *Client) retry(op func() error) error {
+func (c *Client) retry(op string, fn func() error) error {
 	var err error
 	for attempt := 0; attempt < 2; attempt++ {
-		err = op()
+		err = fn()
 		if err == nil {
 			return nil
 		}
+		if c.errorsTotal != nil {
+			c.errorsTotal.WithLabelValues(op).Inc()
+		}
 		if !isConnError(err) {
 			c.log.Debug("retry after err: %+v", err)
 			continue
@@ -124,7 +175,7 @@ func (c *Client) retry(op func() error) error {
 
 // WriteRegister writes a single holding register safely, retrying if needed.
 func (c *Client) WriteRegister(ctx context.Context, addr, value uint16) error {
-	return c.retry(func() error {
+	return c.retry("write_register", func() error {
 		c.mu.Lock()
 		defer c.mu.Unlock()
 		_, err := c.client.WriteSingleRegister(ctx, addr, value)
@@ -135,7 +186,7 @@ func (c *Client) WriteRegister(ctx context.Context, addr, value uint16) error {
 // ReadRegisters reads holding registers safely, retrying if needed.
 func (c *Client) ReadRegisters(ctx context.Context, addr, quantity uint16) ([]byte, error) {
 	var data []byte
-	err := c.retry(func() error {
+	err := c.retry("read_registers", func() error {
 		c.mu.Lock()
 		defer c.mu.Unlock()
 		var rerr error
@@ -145,12 +196,64 @@ func (c *Client) ReadRegisters(ctx context.Context, addr, quantity uint16) ([]by
 	return data, err
 }
 
-// Close closes the underlying handler.
+// ReadInputRegisters reads input registers (function code 0x04) safely,
+// retrying if needed.
+func (c *Client) ReadInputRegisters(ctx context.Context, addr, quantity uint16) ([]byte, error) {
+	var data []byte
+	err := c.retry("read_input_registers", func() error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		var rerr error
+		data, rerr = c.client.ReadInputRegisters(ctx, addr, quantity)
+		return rerr
+	})
+	return data, err
+}
+
+// ReadCoils reads coils (function code 0x01) safely, retrying if needed.
+func (c *Client) ReadCoils(ctx context.Context, addr, quantity uint16) ([]byte, error) {
+	var data []byte
+	err := c.retry("read_coils", func() error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		var rerr error
+		data, rerr = c.client.ReadCoils(ctx, addr, quantity)
+		return rerr
+	})
+	return data, err
+}
+
+// WriteCoil writes a single coil (function code 0x05) safely, retrying if needed.
+func (c *Client) WriteCoil(ctx context.Context, addr uint16, value bool) error {
+	var raw uint16
+	if value {
+		raw = 0xFF00
+	}
+	return c.retry("write_coil", func() error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		_, err := c.client.WriteSingleCoil(ctx, addr, raw)
+		return err
+	})
+}
+
+// WriteMultipleRegisters writes contiguous holding registers (function code
+// 0x10) safely, retrying if needed. raw must be len(quantity)*2 bytes.
+func (c *Client) WriteMultipleRegisters(ctx context.Context, addr, quantity uint16, raw []byte) error {
+	return c.retry("write_multiple_registers", func() error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		_, err := c.client.WriteMultipleRegisters(ctx, addr, quantity, raw)
+		return err
+	})
+}
+
+// Close closes the underlying transport.
 func (c *Client) Close() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.handler != nil {
-		_ = c.handler.Close()
+	if c.transport != nil {
+		_ = c.transport.Close()
 	}
 }
 
@@ -0,0 +1,63 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package modbus
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// WordOrder controls how two 16-bit registers are combined into a 32-bit
+// value: big word first ("big") or little word first ("little"). Byte order
+// within each register is always big-endian, matching the rest of the
+// package.
+type WordOrder int
+
+const (
+	BigWordFirst WordOrder = iota
+	LittleWordFirst
+)
+
+// DecodeU16 decodes a single big-endian holding register into a uint16.
+func DecodeU16(raw []byte) uint16 {
+	return binary.BigEndian.Uint16(raw)
+}
+
+// DecodeS16 decodes a single big-endian holding register into an int16.
+func DecodeS16(raw []byte) int16 {
+	return int16(DecodeU16(raw))
+}
+
+// DecodeU32 decodes two 16-bit registers (4 bytes) into a uint32, honoring order.
+func DecodeU32(raw []byte, order WordOrder) uint32 {
+	hi := binary.BigEndian.Uint16(raw[0:2])
+	lo := binary.BigEndian.Uint16(raw[2:4])
+	if order == LittleWordFirst {
+		hi, lo = lo, hi
+	}
+	return uint32(hi)<<16 | uint32(lo)
+}
+
+// DecodeS32 decodes two 16-bit registers (4 bytes) into an int32, honoring order.
+func DecodeS32(raw []byte, order WordOrder) int32 {
+	return int32(DecodeU32(raw, order))
+}
+
+// DecodeFloat32 decodes two 16-bit registers (4 bytes) into an IEEE-754
+// float32, honoring word order.
+func DecodeFloat32(raw []byte, order WordOrder) float32 {
+	return math.Float32frombits(DecodeU32(raw, order))
+}
@@ -29,21 +29,46 @@ type Config struct {
 }
 
 type ModbusConfig struct {
-	Host    string `yaml:"host"`
-	Port    int    `yaml:"port"`
-	SlaveID byte   `yaml:"slave_id"`
-	Timeout int    `yaml:"timeout"` // seconds
+	// Transport selects the link: "tcp" (default), "rtuovertcp", or "rtu"
+	// (serial, via grid-x/modbus's RTU handler).
+	Transport string `yaml:"transport"`
+
+	// tcp / rtuovertcp
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+
+	// rtu (serial)
+	SerialPort string `yaml:"serial_port"`
+	BaudRate   int    `yaml:"baud_rate"`
+	DataBits   int    `yaml:"data_bits"`
+	Parity     string `yaml:"parity"` // "N", "E", "O"
+	StopBits   int    `yaml:"stop_bits"`
+
+	SlaveID byte `yaml:"slave_id"`
+	Timeout int  `yaml:"timeout"` // seconds
 }
 
 type RegisterDef struct {
 	Address     uint16  `yaml:"address"`
 	Type        string  `yaml:"type"`      // "holding" // not implemented: "input", "coil", "discrete"
-	DataType    string  `yaml:"data_type"` // "uint16", "int16", "bool", "float32" // not implemented: "uint32", "int32",
+	DataType    string  `yaml:"data_type"` // "uint16", "int16", "bool", "float32", "int32", "uint32", "float64"
 	Scale       float64 `yaml:"scale"`     // scaling factor (if set, interprets int16 value as scaled float)
 	Offset      float64 `yaml:"offset"`    // offset value
 	Description string  `yaml:"description"`
 	Writable    bool    `yaml:"writable"`
 	Group       string  `yaml:"group,omitempty"`
+
+	// ByteOrder selects endianness within each 16-bit register: "AB"
+	// (default, big-endian/network order) or "BA" (byte-swapped, as used by
+	// some Modicon and Danfoss devices). Applies to every data type.
+	ByteOrder string `yaml:"byte_order,omitempty"`
+
+	// WordOrder selects which register holds the high-order word of a
+	// 32-bit value: "ABCD" (default, high word first) or "CDAB" (low word
+	// first / word-swapped). Combined with ByteOrder this covers all four
+	// standard 32-bit layouts (ABCD, BADC, CDAB, DCBA). Ignored for 16-bit
+	// data types and for float64, which is always decoded in address order.
+	WordOrder string `yaml:"word_order,omitempty"`
 }
 
 func LoadConfig(filename string) *Config {
@@ -0,0 +1,218 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package modbus
+
+import (
+	"burlo/v2/pkg/logger"
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrShortRead is returned when a batched read didn't return enough bytes
+// to cover one of the ranges it was supposed to satisfy.
+var ErrShortRead = errors.New("modbus: short read")
+
+// maxRegistersPerRead is the largest quantity a single ReadHoldingRegisters
+// call can request per the Modbus PDU limit (253 byte PDU / 2 bytes per
+// register, minus protocol overhead).
+const maxRegistersPerRead = 125
+
+// ScanResult is fanned out to subscribers once per decoded named range.
+type ScanResult struct {
+	Name      string
+	Raw       []byte
+	Timestamp time.Time
+	Err       error
+}
+
+// scanRange describes one named register range tracked by a Scanner.
+type scanRange struct {
+	name     string
+	address  uint16
+	quantity uint16
+	interval time.Duration
+	nextDue  time.Time
+}
+
+// Scanner batches polling of many small, independently-scheduled register
+// ranges into as few ReadHoldingRegisters calls as possible, which matters a
+// lot on slow serial links where every round trip costs tens of
+// milliseconds. Callers register named ranges once and receive decoded
+// results through a subscription channel; Scanner owns the scheduling.
+type Scanner struct {
+	client  *Client
+	maxGap  uint16
+	ranges  []*scanRange
+	results chan ScanResult
+	log     *logger.Logger
+}
+
+// NewScanner creates a Scanner over an existing Client. maxGap is the
+// largest address gap between two ranges that is still cheaper to bridge
+// into a single read than to issue as two separate round trips.
+func NewScanner(client *Client, maxGap uint16) *Scanner {
+	return &Scanner{
+		client:  client,
+		maxGap:  maxGap,
+		results: make(chan ScanResult, 64),
+		log:     logger.New("ModbusScanner"),
+	}
+}
+
+// Register adds a named holding-register range to be polled at interval.
+// Must be called before Run.
+func (s *Scanner) Register(name string, address, quantity uint16, interval time.Duration) {
+	s.ranges = append(s.ranges, &scanRange{
+		name:     name,
+		address:  address,
+		quantity: quantity,
+		interval: interval,
+	})
+}
+
+// Subscribe returns the channel ScanResults are published on. The channel is
+// shared by all subscribers; callers that need independent fan-out should
+// run their own broadcaster on top of it.
+func (s *Scanner) Subscribe() <-chan ScanResult {
+	return s.results
+}
+
+// Run polls registered ranges until ctx is canceled, coalescing whichever
+// ranges are due on a given tick into the minimum number of reads.
+func (s *Scanner) Run(ctx context.Context) {
+	if len(s.ranges) == 0 {
+		s.log.Info("no ranges registered, nothing to do")
+		return
+	}
+
+	tick := s.fastestInterval()
+	s.log.Info("Running (%d ranges, tick %v)...", len(s.ranges), tick)
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	now := time.Now()
+	for _, r := range s.ranges {
+		r.nextDue = now
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("Stopped")
+			return
+		case now := <-ticker.C:
+			s.scanDue(ctx, now)
+		}
+	}
+}
+
+func (s *Scanner) fastestInterval() time.Duration {
+	fastest := s.ranges[0].interval
+	for _, r := range s.ranges[1:] {
+		if r.interval < fastest {
+			fastest = r.interval
+		}
+	}
+	return fastest
+}
+
+func (s *Scanner) scanDue(ctx context.Context, now time.Time) {
+	var due []*scanRange
+	for _, r := range s.ranges {
+		if !now.Before(r.nextDue) {
+			due = append(due, r)
+			r.nextDue = now.Add(r.interval)
+		}
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	for _, batch := range coalesce(due, s.maxGap) {
+		s.readBatch(ctx, batch)
+	}
+}
+
+// batch is a contiguous span of the device's address space covering one or
+// more scanRanges, sized to fit within a single ReadHoldingRegisters call.
+type batch struct {
+	address  uint16
+	quantity uint16
+	ranges   []*scanRange
+}
+
+// coalesce sorts the due ranges by address and greedily merges neighbours
+// that are adjacent or within maxGap registers of each other, splitting
+// again wherever the merged span would exceed maxRegistersPerRead.
+func coalesce(due []*scanRange, maxGap uint16) []batch {
+	sorted := append([]*scanRange(nil), due...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].address < sorted[j].address })
+
+	var batches []batch
+	cur := batch{address: sorted[0].address, quantity: sorted[0].quantity, ranges: []*scanRange{sorted[0]}}
+
+	for _, r := range sorted[1:] {
+		curEnd := cur.address + cur.quantity
+		rEnd := r.address + r.quantity
+		gap := int(r.address) - int(curEnd)
+
+		merged := rEnd - cur.address
+		if gap <= int(maxGap) && merged <= maxRegistersPerRead {
+			if rEnd > curEnd {
+				cur.quantity = merged
+			}
+			cur.ranges = append(cur.ranges, r)
+			continue
+		}
+
+		batches = append(batches, cur)
+		cur = batch{address: r.address, quantity: r.quantity, ranges: []*scanRange{r}}
+	}
+	batches = append(batches, cur)
+	return batches
+}
+
+func (s *Scanner) readBatch(ctx context.Context, b batch) {
+	ts := time.Now()
+	raw, err := s.client.ReadRegisters(ctx, b.address, b.quantity)
+	if err != nil {
+		for _, r := range b.ranges {
+			s.publish(ScanResult{Name: r.name, Timestamp: ts, Err: err})
+		}
+		return
+	}
+
+	for _, r := range b.ranges {
+		start := (r.address - b.address) * 2
+		end := start + r.quantity*2
+		if int(end) > len(raw) {
+			s.publish(ScanResult{Name: r.name, Timestamp: ts, Err: ErrShortRead})
+			continue
+		}
+		s.publish(ScanResult{Name: r.name, Raw: raw[start:end], Timestamp: ts})
+	}
+}
+
+func (s *Scanner) publish(res ScanResult) {
+	select {
+	case s.results <- res:
+	default:
+		s.log.Error("subscriber channel full, dropping result for %q", res.Name)
+	}
+}
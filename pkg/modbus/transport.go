@@ -0,0 +1,78 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package modbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	wrapper "github.com/grid-x/modbus"
+)
+
+// Transport is the minimal handler surface Client needs to manage the
+// underlying link, regardless of whether it's TCP, RTU-over-TCP, or serial
+// RTU. All three grid-x/modbus handler types satisfy it.
+type Transport interface {
+	Connect(ctx context.Context) error
+	Close() error
+}
+
+// newTransport builds the handler (and the wrapper.Client bound to it) for
+// the transport selected by cfg.Transport. Default is "tcp", preserving the
+// original hard-wired behavior.
+func newTransport(cfg ModbusConfig) (Transport, wrapper.Client, error) {
+	timeout := time.Second * time.Duration(cfg.Timeout)
+
+	switch cfg.Transport {
+	case "", "tcp":
+		url := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+		h := wrapper.NewTCPClientHandler(url)
+		h.SlaveID = cfg.SlaveID
+		h.Timeout = timeout
+		h.ProtocolRecoveryTimeout = 250 * time.Millisecond
+		h.LinkRecoveryTimeout = 5 * time.Second
+		return h, wrapper.NewClient(h), nil
+
+	case "rtuovertcp":
+		url := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+		h := wrapper.NewRTUOverTCPClientHandler(url)
+		h.SlaveID = cfg.SlaveID
+		h.Timeout = timeout
+		return h, wrapper.NewClient(h), nil
+
+	case "rtu":
+		h := wrapper.NewRTUClientHandler(cfg.SerialPort)
+		h.SlaveID = cfg.SlaveID
+		h.Timeout = timeout
+		if cfg.BaudRate > 0 {
+			h.BaudRate = cfg.BaudRate
+		}
+		if cfg.DataBits > 0 {
+			h.DataBits = cfg.DataBits
+		}
+		if cfg.Parity != "" {
+			h.Parity = cfg.Parity
+		}
+		if cfg.StopBits > 0 {
+			h.StopBits = cfg.StopBits
+		}
+		return h, wrapper.NewClient(h), nil
+
+	default:
+		return nil, nil, fmt.Errorf("unsupported modbus transport %q", cfg.Transport)
+	}
+}
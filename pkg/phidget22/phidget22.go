@@ -0,0 +1,364 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package phidget22 is a minimal client for the Phidget22 Network Server
+// protocol, used in place of the Phidget22 language bindings (which this
+// tree avoids linking via cgo). A Phidget22 network server - the
+// "phidget22network" daemon bundled with every Phidget22 install, or a
+// VINT hub running in network-server mode - accepts a TCP connection per
+// client and exchanges newline-delimited JSON packets describing channel
+// opens, writes, and input events. This client implements just the subset
+// needed by this repo: digital outputs, voltage outputs, and digital
+// inputs.
+package phidget22
+
+import (
+	"bufio"
+	"burlo/v2/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	dialTimeout = 5 * time.Second
+	minBackoff  = 1 * time.Second
+	maxBackoff  = 30 * time.Second
+
+	maxQueuedWrites = 32
+)
+
+const (
+	classDigitalOutput = "digital_output"
+	classVoltageOutput = "voltage_output"
+	classDigitalInput  = "digital_input"
+)
+
+// frame is one line of the wire protocol: a command ("open", "set") sent
+// to the server, or a report ("event") received from it, always scoped to
+// one channel by class/channel/hub port.
+type frame struct {
+	Op      string          `json:"op"`
+	Class   string          `json:"class"`
+	Channel int             `json:"channel"`
+	HubPort int             `json:"hub_port"`
+	Value   json.RawMessage `json:"value,omitempty"`
+}
+
+type channelKey struct {
+	class   string
+	channel int
+	hubPort int
+}
+
+// Event is a single state change reported by a DigitalInChannel.
+type Event struct {
+	State bool
+	Time  time.Time
+}
+
+// Client manages the TCP connection to a Phidget22 network server,
+// reconnecting with backoff on failure. It follows the same shape as
+// zwavejsws.Client: callers register interest (DigitalIn) or issue writes
+// (DigitalOut/VoltageOut.Set) regardless of whether the connection is
+// currently up, and Client queues or replays as needed.
+type Client struct {
+	addr string
+	log  *logger.Logger
+
+	mu          sync.Mutex
+	conn        net.Conn
+	onConnState func(bool)
+
+	inputsMu sync.Mutex
+	inputs   map[channelKey]chan Event
+
+	writeMu    sync.Mutex
+	writeQueue []frame
+}
+
+// NewClient builds a Client that dials the Phidget22 network server at
+// addr (host:port).
+func NewClient(addr string) *Client {
+	return &Client{
+		addr:   addr,
+		log:    logger.New("Phidget22 ").With("addr", addr),
+		inputs: make(map[channelKey]chan Event),
+	}
+}
+
+// OnConnState sets the callback invoked with true right after Run
+// establishes a connection and with false right after it's lost, so
+// callers (e.g. the Phidgets manager) can surface "device offline".
+func (c *Client) OnConnState(fn func(bool)) {
+	c.onConnState = fn
+}
+
+func (c *Client) setConnState(connected bool) {
+	if c.onConnState != nil {
+		c.onConnState(connected)
+	}
+}
+
+// Run dials addr and serves the connection until ctx is canceled,
+// reconnecting with exponential backoff on any failure. It re-opens every
+// previously-requested DigitalIn channel and replays any queued writes on
+// each reconnect, so callers don't need to notice a drop.
+func (c *Client) Run(ctx context.Context) {
+	backoff := minBackoff
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		conn, err := net.DialTimeout("tcp", c.addr, dialTimeout)
+		if err != nil {
+			c.log.Error("connect failed: %v, retrying in %s", err, backoff)
+			if !sleepBackoff(ctx, backoff) {
+				return
+			}
+			backoff = nextBackoff(backoff)
+			continue
+		}
+		backoff = minBackoff
+
+		c.log.Info("connected")
+		c.mu.Lock()
+		c.conn = conn
+		c.mu.Unlock()
+		c.setConnState(true)
+		c.reopenInputs()
+		c.drainWriteQueue()
+
+		c.readLoop(conn)
+
+		c.mu.Lock()
+		c.conn = nil
+		c.mu.Unlock()
+		c.setConnState(false)
+		conn.Close()
+		c.log.Error("connection lost")
+	}
+}
+
+// readLoop consumes frames from conn until it closes or errors, dispatching
+// "event" frames to the matching DigitalIn channel.
+func (c *Client) readLoop(conn net.Conn) {
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var f frame
+		if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			c.log.Error("malformed frame: %v", err)
+			continue
+		}
+		if f.Op != "event" {
+			continue
+		}
+
+		var state bool
+		if err := json.Unmarshal(f.Value, &state); err != nil {
+			c.log.Error("malformed event value: %v", err)
+			continue
+		}
+
+		key := channelKey{f.Class, f.Channel, f.HubPort}
+		c.inputsMu.Lock()
+		events := c.inputs[key]
+		c.inputsMu.Unlock()
+		if events == nil {
+			continue
+		}
+
+		select {
+		case events <- Event{State: state, Time: time.Now()}:
+		default:
+			c.log.Debug("digital input %d/%d event channel full; dropping", f.Channel, f.HubPort)
+		}
+	}
+}
+
+func (c *Client) send(f frame) error {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		c.queueWrite(f)
+		return nil
+	}
+	return c.writeFrame(conn, f)
+}
+
+func (c *Client) writeFrame(conn net.Conn, f frame) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to marshal frame: %w", err)
+	}
+	data = append(data, '\n')
+	_, err = conn.Write(data)
+	return err
+}
+
+// queueWrite appends f to the bounded write queue, dropping the oldest
+// entry if it's full.
+func (c *Client) queueWrite(f frame) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if len(c.writeQueue) >= maxQueuedWrites {
+		c.writeQueue = c.writeQueue[1:]
+	}
+	c.writeQueue = append(c.writeQueue, f)
+}
+
+// drainWriteQueue replays any writes queued while disconnected, in order,
+// right after Run re-establishes a connection.
+func (c *Client) drainWriteQueue() {
+	c.writeMu.Lock()
+	queue := c.writeQueue
+	c.writeQueue = nil
+	c.writeMu.Unlock()
+
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		return
+	}
+	for _, f := range queue {
+		if err := c.writeFrame(conn, f); err != nil {
+			c.log.Error("failed to replay queued write: %v", err)
+		}
+	}
+}
+
+// openInput sends the "open" command for key; it's a no-op write like any
+// other, so it goes through the same queue-while-disconnected path.
+func (c *Client) openInput(key channelKey) {
+	c.send(frame{Op: "open", Class: key.class, Channel: key.channel, HubPort: key.hubPort})
+}
+
+// reopenInputs re-sends "open" for every DigitalIn channel a caller has
+// requested, so event delivery resumes after a reconnect.
+func (c *Client) reopenInputs() {
+	c.inputsMu.Lock()
+	keys := make([]channelKey, 0, len(c.inputs))
+	for k := range c.inputs {
+		keys = append(keys, k)
+	}
+	c.inputsMu.Unlock()
+
+	for _, k := range keys {
+		c.openInput(k)
+	}
+}
+
+// DigitalOutChannel drives a single digital output channel.
+type DigitalOutChannel struct {
+	c       *Client
+	channel int
+	hubPort int
+}
+
+// DigitalOut returns a handle to the digital output at channel/hubPort.
+func (c *Client) DigitalOut(channel, hubPort int) *DigitalOutChannel {
+	return &DigitalOutChannel{c: c, channel: channel, hubPort: hubPort}
+}
+
+// Set drives the output high (true) or low (false).
+func (ch *DigitalOutChannel) Set(state bool) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	return ch.c.send(frame{Op: "set", Class: classDigitalOutput, Channel: ch.channel, HubPort: ch.hubPort, Value: value})
+}
+
+// VoltageOutChannel drives a single analog voltage output channel.
+type VoltageOutChannel struct {
+	c       *Client
+	channel int
+	hubPort int
+}
+
+// VoltageOut returns a handle to the voltage output at channel/hubPort.
+func (c *Client) VoltageOut(channel, hubPort int) *VoltageOutChannel {
+	return &VoltageOutChannel{c: c, channel: channel, hubPort: hubPort}
+}
+
+// Set drives the output to voltage volts.
+func (ch *VoltageOutChannel) Set(voltage float64) error {
+	value, err := json.Marshal(voltage)
+	if err != nil {
+		return fmt.Errorf("failed to marshal voltage: %w", err)
+	}
+	return ch.c.send(frame{Op: "set", Class: classVoltageOutput, Channel: ch.channel, HubPort: ch.hubPort, Value: value})
+}
+
+// DigitalInChannel reports state changes on a single digital input channel.
+type DigitalInChannel struct {
+	events <-chan Event
+}
+
+// DigitalIn opens (and, on every reconnect, re-opens) the digital input at
+// channel/hubPort and returns a handle whose Events channel carries every
+// state change the server reports for it. Calling DigitalIn again for the
+// same channel/hubPort returns a handle sharing the same underlying
+// channel rather than opening it twice.
+func (c *Client) DigitalIn(channel, hubPort int) *DigitalInChannel {
+	key := channelKey{classDigitalInput, channel, hubPort}
+
+	c.inputsMu.Lock()
+	events, ok := c.inputs[key]
+	if !ok {
+		events = make(chan Event, 8)
+		c.inputs[key] = events
+	}
+	c.inputsMu.Unlock()
+
+	if !ok {
+		c.openInput(key)
+	}
+	return &DigitalInChannel{events: events}
+}
+
+// Events returns the channel this input's state changes are delivered on.
+func (ch *DigitalInChannel) Events() <-chan Event {
+	return ch.events
+}
+
+// sleepBackoff waits backoff plus jitter, returning false if ctx is
+// canceled first.
+func sleepBackoff(ctx context.Context, backoff time.Duration) bool {
+	jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(backoff + jitter):
+		return true
+	}
+}
+
+func nextBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rootserv
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// RunAutoCert serves HTTPS on ms.addr using a Let's Encrypt certificate for
+// domain, automatically obtained and renewed, with certs cached under
+// cacheDir. A plain HTTP server on :80 answers ACME HTTP-01 challenges and
+// redirects everything else to HTTPS.
+func (ms *RootServer) RunAutoCert(ctx context.Context, domain, cacheDir string) {
+	ms.log.Info("Running (autocert, domain=%s)...", domain)
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domain),
+		Cache:      autocert.DirCache(cacheDir),
+	}
+
+	challengeSrv := &http.Server{Addr: ":80", Handler: manager.HTTPHandler(nil)}
+	go challengeSrv.ListenAndServe()
+	defer challengeSrv.Close()
+
+	srv := &http.Server{
+		Addr:      ms.addr,
+		Handler:   ms.buildHandler(),
+		TLSConfig: manager.TLSConfig(),
+	}
+	ms.serve(ctx, srv, func() error {
+		return srv.ListenAndServeTLS("", "")
+	})
+}
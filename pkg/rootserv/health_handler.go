@@ -0,0 +1,75 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rootserv
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// HealthChecker is the duck-typed interface a service optionally implements
+// to participate in HealthHandler's aggregate report. It's deliberately
+// decoupled from service.Runnable: not every Runnable has a meaningful
+// health signal, and some checks (e.g. disk space) aren't Runnables at all.
+type HealthChecker interface {
+	Health() error
+}
+
+// healthStatus is one service's entry in the /healthz response.
+type healthStatus struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// healthResponse is the /healthz response body.
+type healthResponse struct {
+	OK       bool           `json:"ok"`
+	Services []healthStatus `json:"services"`
+}
+
+// HealthHandler aggregates checks into a single report: HTTP 200 with
+// per-service status when every check passes, 503 otherwise, so a
+// supervisor or load balancer can gate on status code alone. Intended to
+// be attached at "/healthz".
+func HealthHandler(checks map[string]HealthChecker) http.HandlerFunc {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := healthResponse{OK: true, Services: make([]healthStatus, 0, len(names))}
+
+		for _, name := range names {
+			status := healthStatus{Name: name, OK: true}
+			if err := checks[name].Health(); err != nil {
+				status.OK = false
+				status.Error = err.Error()
+				resp.OK = false
+			}
+			resp.Services = append(resp.Services, status)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if !resp.OK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}
+}
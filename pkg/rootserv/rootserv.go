@@ -17,40 +17,67 @@ package rootserv
 
 import (
 	"burlo/v2/pkg/logger"
+	"burlo/v2/pkg/sysmon"
 	"context"
 	"fmt"
 	"net/http"
 	"sort"
 	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// subserver tracks what's needed to render and gate one entry in the index.
+type subserver struct {
+	desc       string
+	authorized func(r *http.Request) bool // nil means always visible
+}
+
 // RootServer holds a mux and the list of attached sub-handlers.
 type RootServer struct {
 	log        *logger.Logger
 	addr       string
 	mux        *http.ServeMux
-	subservers map[string]string // path -> description
-	mainPage   http.Handler      // optional subserver for '/'
+	subservers map[string]subserver // path -> subserver
+	mainPage   http.Handler         // optional subserver for '/'
+	requests   *prometheus.CounterVec
+	middleware []Middleware // global, applied to every request
 }
 
-// New creates a new RootServer bound to an address.
-func New(addr string) *RootServer {
+// New creates a new RootServer bound to an address. reg receives a
+// burlo_http_requests_total counter, labeled by subserver path, so scrape
+// traffic shows up alongside every other subsystem's metrics.
+func New(addr string, reg *sysmon.Registry) *RootServer {
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "burlo_http_requests_total",
+		Help: "Total HTTP requests handled per subserver path.",
+	}, []string{"path"})
+	reg.MustRegister(requests)
+
 	return &RootServer{
 		addr:       addr,
 		mux:        http.NewServeMux(),
-		subservers: make(map[string]string),
+		subservers: make(map[string]subserver),
 		log:        logger.New("HTTPServer"),
+		requests:   requests,
 	}
 }
 
-// Attach registers a new subserver under a path.
+// Use registers a global middleware, applied to every request regardless of
+// which subserver handles it. Middlewares run in the order they're added.
+func (ms *RootServer) Use(mw Middleware) {
+	ms.middleware = append(ms.middleware, mw)
+}
+
+// Attach registers a new subserver under a path, optionally wrapped in its
+// own middleware chain (e.g. BasicAuth for that subserver only).
 // If path == "/", it becomes the main page and can handle its own subpaths.
-func (ms *RootServer) Attach(path, desc string, handler http.Handler) {
+func (ms *RootServer) Attach(path, desc string, handler http.Handler, mw ...Middleware) {
 	ms.log.Info("Attach: %s", path)
 
 	// Root handler special case
 	if path == "/" {
-		ms.mainPage = handler
+		ms.mainPage = chain(handler, mw)
 		ms.log.Info("Main page registered at /")
 		return
 	}
@@ -65,14 +92,37 @@ func (ms *RootServer) Attach(path, desc string, handler http.Handler) {
 		path += "/"
 	}
 
-	ms.subservers[strings.TrimRight(path, "/")] = desc // store pretty form
+	pretty := strings.TrimRight(path, "/")
+	ms.subservers[pretty] = subserver{desc: desc} // store pretty form
+
+	wrapped := chain(handler, mw)
+	counter := ms.requests.WithLabelValues(pretty)
+	counted := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter.Inc()
+		wrapped.ServeHTTP(w, r)
+	})
 
 	// Strip the prefix (without trailing slash) so subserver sees clean URLs.
-	strip := strings.TrimRight(path, "/")
-	ms.mux.Handle(path, http.StripPrefix(strip, handler))
+	ms.mux.Handle(path, http.StripPrefix(pretty, counted))
+}
+
+// AttachProtected is Attach plus an HTTP basic-auth gate; unauthenticated
+// visitors neither reach the handler nor see the subserver listed on the
+// index page.
+func (ms *RootServer) AttachProtected(path, desc, realm, user, pass string, handler http.Handler, mw ...Middleware) {
+	authMw, authorized := BasicAuth(realm, user, pass)
+	ms.Attach(path, desc, handler, append([]Middleware{authMw}, mw...)...)
+
+	pretty := strings.TrimRight("/"+strings.TrimLeft(path, "/"), "/")
+	if sub, ok := ms.subservers[pretty]; ok {
+		sub.authorized = authorized
+		ms.subservers[pretty] = sub
+	}
 }
 
-// handleIndex generates the HTML index page listing all subservers.
+// handleIndex generates the HTML index page listing all subservers the
+// requester is allowed to see; protected subservers the request isn't
+// authorized for are omitted entirely rather than just disabled.
 func (ms *RootServer) handleIndex(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
@@ -80,23 +130,25 @@ func (ms *RootServer) handleIndex(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintln(w, "<h1>Available Sub-Servers</h1><ul>")
 
 	paths := make([]string, 0, len(ms.subservers))
-	for path := range ms.subservers {
+	for path, sub := range ms.subservers {
+		if sub.authorized != nil && !sub.authorized(r) {
+			continue
+		}
 		paths = append(paths, path)
 	}
 	sort.Strings(paths)
 
 	for _, path := range paths {
-		desc := ms.subservers[path]
+		desc := ms.subservers[path].desc
 		fmt.Fprintf(w, `<li><a href="%s">%s</a> - %s</li>`, path, path, desc)
 	}
 
 	fmt.Fprintln(w, "</ul></body></html>")
 }
 
-// Run starts serving and blocks until the context is canceled.
-func (ms *RootServer) Run(ctx context.Context) {
-	ms.log.Info("Running...")
-
+// buildHandler finishes wiring the mux (index, favicon, root fallback) and
+// wraps it in the global middleware chain. Shared by Run and RunTLS.
+func (ms *RootServer) buildHandler() http.Handler {
 	// index page always available
 	ms.mux.HandleFunc("/index", ms.handleIndex)
 
@@ -124,14 +176,14 @@ func (ms *RootServer) Run(ctx context.Context) {
 		http.Redirect(w, r, "/index", http.StatusTemporaryRedirect)
 	})
 
-	srv := &http.Server{
-		Addr:    ms.addr,
-		Handler: ms.mux,
-	}
+	return chain(ms.mux, ms.middleware)
+}
 
+// serve runs srv until ctx is canceled, then gracefully shuts it down.
+func (ms *RootServer) serve(ctx context.Context, srv *http.Server, listenAndServe func() error) {
 	errCh := make(chan error, 1)
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := listenAndServe(); err != nil && err != http.ErrServerClosed {
 			errCh <- err
 		}
 		close(errCh)
@@ -147,3 +199,19 @@ func (ms *RootServer) Run(ctx context.Context) {
 		ms.log.Error("Stopped: %T %+v", err, err)
 	}
 }
+
+// Run starts serving plain HTTP and blocks until the context is canceled.
+func (ms *RootServer) Run(ctx context.Context) {
+	ms.log.Info("Running...")
+	srv := &http.Server{Addr: ms.addr, Handler: ms.buildHandler()}
+	ms.serve(ctx, srv, srv.ListenAndServe)
+}
+
+// RunTLS is Run but serving HTTPS with a static certificate/key pair.
+func (ms *RootServer) RunTLS(ctx context.Context, certFile, keyFile string) {
+	ms.log.Info("Running (TLS)...")
+	srv := &http.Server{Addr: ms.addr, Handler: ms.buildHandler()}
+	ms.serve(ctx, srv, func() error {
+		return srv.ListenAndServeTLS(certFile, keyFile)
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rootserv
+
+import (
+	"burlo/v2/pkg/authz"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// TokenRequest is the body posted to TokenHandler: a username/password pair
+// checked against the config-defined credentials for scope.
+type TokenRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// TokenResponse carries the minted token back to the browser app.
+type TokenResponse struct {
+	Token     string `json:"token"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+// TokenHandler mints scope-bound JWTs for clients that authenticate with
+// username/password, e.g. the thermostat web app fetching a token for its
+// websocket connection on page load. Intended to be attached at
+// "/auth/token".
+func TokenHandler(issuer *authz.Issuer, username, password, scope string, ttl time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req TokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(req.Username), []byte(username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(req.Password), []byte(password)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		token, err := issuer.Issue(scope, ttl)
+		if err != nil {
+			http.Error(w, "failed to issue token", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(TokenResponse{Token: token, ExpiresIn: int(ttl.Seconds())})
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// DX2WServiceName is the gRPC service name DX2WServer registers under.
+const DX2WServiceName = "rpc.DX2W"
+
+const (
+	methodSetOutdoorAirDesignTempC     = "SetOutdoorAirDesignTempC"
+	methodSetHotWaterDesignTempC       = "SetHotWaterDesignTempC"
+	methodSetHotWaterMinTempC          = "SetHotWaterMinTempC"
+	methodSetHotWaterDifferentialTempC = "SetHotWaterDifferentialTempC"
+	methodReadRegister                 = "ReadRegister"
+	methodWriteRegister                = "WriteRegister"
+)
+
+// DX2WServer is implemented by internal/dx2w to expose its package-level
+// setpoint setters and the underlying modbus.Client.ReadValue/WriteValue
+// paths over gRPC.
+type DX2WServer interface {
+	SetOutdoorAirDesignTempC(context.Context, *FloatValue) (*Empty, error)
+	SetHotWaterDesignTempC(context.Context, *FloatValue) (*Empty, error)
+	SetHotWaterMinTempC(context.Context, *FloatValue) (*Empty, error)
+	SetHotWaterDifferentialTempC(context.Context, *FloatValue) (*Empty, error)
+	ReadRegister(context.Context, *RegisterRequest) (*RegisterValue, error)
+	WriteRegister(context.Context, *WriteRegisterRequest) (*Empty, error)
+}
+
+func dx2wFloatSetterHandler(method string, call func(DX2WServer, context.Context, *FloatValue) (*Empty, error)) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		req := new(FloatValue)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return call(srv.(DX2WServer), ctx, req)
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + DX2WServiceName + "/" + method}
+		handler := func(ctx context.Context, req any) (any, error) {
+			return call(srv.(DX2WServer), ctx, req.(*FloatValue))
+		}
+		return interceptor(ctx, req, info, handler)
+	}
+}
+
+func dx2wReadRegisterHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	req := new(RegisterRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DX2WServer).ReadRegister(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + DX2WServiceName + "/" + methodReadRegister}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(DX2WServer).ReadRegister(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+func dx2wWriteRegisterHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	req := new(WriteRegisterRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DX2WServer).WriteRegister(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + DX2WServiceName + "/" + methodWriteRegister}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(DX2WServer).WriteRegister(ctx, req.(*WriteRegisterRequest))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+// DX2WServiceDesc is the hand-rolled equivalent of what
+// protoc-gen-go-grpc would emit for DX2WServer.
+var DX2WServiceDesc = grpc.ServiceDesc{
+	ServiceName: DX2WServiceName,
+	HandlerType: (*DX2WServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: methodSetOutdoorAirDesignTempC, Handler: dx2wFloatSetterHandler(methodSetOutdoorAirDesignTempC, DX2WServer.SetOutdoorAirDesignTempC)},
+		{MethodName: methodSetHotWaterDesignTempC, Handler: dx2wFloatSetterHandler(methodSetHotWaterDesignTempC, DX2WServer.SetHotWaterDesignTempC)},
+		{MethodName: methodSetHotWaterMinTempC, Handler: dx2wFloatSetterHandler(methodSetHotWaterMinTempC, DX2WServer.SetHotWaterMinTempC)},
+		{MethodName: methodSetHotWaterDifferentialTempC, Handler: dx2wFloatSetterHandler(methodSetHotWaterDifferentialTempC, DX2WServer.SetHotWaterDifferentialTempC)},
+		{MethodName: methodReadRegister, Handler: dx2wReadRegisterHandler},
+		{MethodName: methodWriteRegister, Handler: dx2wWriteRegisterHandler},
+	},
+}
+
+// RegisterDX2WServer registers srv as the DX2W service on s.
+func RegisterDX2WServer(s grpc.ServiceRegistrar, srv DX2WServer) {
+	s.RegisterService(&DX2WServiceDesc, srv)
+}
+
+// ---------- Client side ----------
+
+// DX2WClient dials out to a DX2W service.
+type DX2WClient struct {
+	cc *grpc.ClientConn
+}
+
+// NewDX2WClient wraps an established connection.
+func NewDX2WClient(cc *grpc.ClientConn) *DX2WClient {
+	return &DX2WClient{cc: cc}
+}
+
+func (c *DX2WClient) invokeFloatSetter(ctx context.Context, method string, req *FloatValue, opts []grpc.CallOption) (*Empty, error) {
+	resp := new(Empty)
+	if err := c.cc.Invoke(ctx, "/"+DX2WServiceName+"/"+method, req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *DX2WClient) SetOutdoorAirDesignTempC(ctx context.Context, req *FloatValue, opts ...grpc.CallOption) (*Empty, error) {
+	return c.invokeFloatSetter(ctx, methodSetOutdoorAirDesignTempC, req, opts)
+}
+
+func (c *DX2WClient) SetHotWaterDesignTempC(ctx context.Context, req *FloatValue, opts ...grpc.CallOption) (*Empty, error) {
+	return c.invokeFloatSetter(ctx, methodSetHotWaterDesignTempC, req, opts)
+}
+
+func (c *DX2WClient) SetHotWaterMinTempC(ctx context.Context, req *FloatValue, opts ...grpc.CallOption) (*Empty, error) {
+	return c.invokeFloatSetter(ctx, methodSetHotWaterMinTempC, req, opts)
+}
+
+func (c *DX2WClient) SetHotWaterDifferentialTempC(ctx context.Context, req *FloatValue, opts ...grpc.CallOption) (*Empty, error) {
+	return c.invokeFloatSetter(ctx, methodSetHotWaterDifferentialTempC, req, opts)
+}
+
+func (c *DX2WClient) ReadRegister(ctx context.Context, req *RegisterRequest, opts ...grpc.CallOption) (*RegisterValue, error) {
+	resp := new(RegisterValue)
+	if err := c.cc.Invoke(ctx, "/"+DX2WServiceName+"/"+methodReadRegister, req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *DX2WClient) WriteRegister(ctx context.Context, req *WriteRegisterRequest, opts ...grpc.CallOption) (*Empty, error) {
+	resp := new(Empty)
+	if err := c.cc.Invoke(ctx, "/"+DX2WServiceName+"/"+methodWriteRegister, req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
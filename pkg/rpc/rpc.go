@@ -0,0 +1,120 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package rpc defines a gRPC control-plane surface mirroring the
+// in-process interactions that today only work through clientQueue and
+// the eventbus: a Thermostat service (GetState/SetSetpoint/SetMode plus a
+// server-streaming Subscribe) and a DX2W service (typed setpoint setters
+// plus a generic ReadRegister/WriteRegister pair). This lets a remote
+// scheduler, dashboard, or future controller drive burlo out-of-process.
+//
+// As with pkg/agentrpc, messages are plain JSON carried over grpc via a
+// custom codec rather than protoc-generated protobuf, since this repo has
+// no protobuf/protoc toolchain in its build. pkg/rpc doesn't share
+// agentrpc's codec registration (keeping the two protocols independent),
+// but follows the same hand-rolled-ServiceDesc pattern.
+//
+// The service implementations live in internal/thermostat and
+// internal/dx2w, which adapt this package's server interfaces onto their
+// existing state (clientQueue, the eventbus, the dx2w package-level
+// ModbusClient); pkg/rpc itself only defines the wire protocol, since
+// pkg/ never imports internal/.
+package rpc
+
+import (
+	"fmt"
+	"time"
+)
+
+// Empty is the request/response for RPCs that carry no data.
+type Empty struct{}
+
+// ThermostatState mirrors thermostat.WebAppState, without coupling pkg/rpc
+// to internal/thermostat's package.
+type ThermostatState struct {
+	TemperatureC          float64   `json:"temperature_c"`
+	SetpointC             float64   `json:"setpoint_c"`
+	Humidity              float64   `json:"humidity"`
+	Mode                  int       `json:"mode"`
+	State                 int       `json:"state"`
+	Battery               float64   `json:"battery"`
+	BatteryLow            bool      `json:"battery_low"`
+	Valve                 float64   `json:"valve"`
+	EcoSetpointC          float64   `json:"eco_setpoint_c"`
+	BoostRemainingSeconds float64   `json:"boost_remaining_seconds"`
+	ZWaveOnline           bool      `json:"zwave_online"`
+	Timestamp             time.Time `json:"timestamp"`
+}
+
+// SetSetpointRequest is SetSetpoint's argument: DeltaC is added to the
+// current setpoint, matching the existing "change_setpoint" WebAppRequest
+// semantics (absolute setpoints aren't supported client-side today).
+type SetSetpointRequest struct {
+	DeltaC float64 `json:"delta_c"`
+}
+
+// SetModeRequest is SetMode's argument. Mode toggles between off/heat
+// today (see VTMode.toggle), so this carries no fields yet; it exists so
+// the wire shape doesn't need to change if toggle is ever replaced with an
+// explicit target mode.
+type SetModeRequest struct{}
+
+// FloatValue is the argument to DX2W's single-float setpoint setters.
+type FloatValue struct {
+	ValueC float64 `json:"value_c"`
+}
+
+// RegisterRequest names a configured Modbus register for ReadRegister.
+type RegisterRequest struct {
+	Register string `json:"register"`
+}
+
+// WriteRegisterRequest writes an arbitrary value to a named register; the
+// server rejects it if the value doesn't match the register's configured
+// data type (mirroring modbus.Client.WriteValue's own validation).
+type WriteRegisterRequest struct {
+	Register string `json:"register"`
+	Value    any    `json:"value"`
+}
+
+// RegisterValue is a decoded register reading. Kind names which of the
+// typed fields is populated, simulating a protobuf oneof without an
+// actual oneof (no protoc in this build — see the package doc comment).
+type RegisterValue struct {
+	Kind    string  `json:"kind"` // "float32", "int16", "uint16", or "bool"
+	Float32 float32 `json:"float32,omitempty"`
+	Int16   int16   `json:"int16,omitempty"`
+	Uint16  uint16  `json:"uint16,omitempty"`
+	Bool    bool    `json:"bool,omitempty"`
+}
+
+// NewRegisterValue converts a modbus.Client.ReadValue result of type
+// float32, int16, uint16, or bool into a RegisterValue. The float64, int32
+// and uint32 values ReadValue also returns are not converted; for those it
+// returns an error.
+func NewRegisterValue(v any) (*RegisterValue, error) {
+	switch val := v.(type) {
+	case float32:
+		return &RegisterValue{Kind: "float32", Float32: val}, nil
+	case int16:
+		return &RegisterValue{Kind: "int16", Int16: val}, nil
+	case uint16:
+		return &RegisterValue{Kind: "uint16", Uint16: val}, nil
+	case bool:
+		return &RegisterValue{Kind: "bool", Bool: val}, nil
+	default:
+		return nil, fmt.Errorf("unsupported register value type %T", v)
+	}
+}
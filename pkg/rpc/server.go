@@ -0,0 +1,156 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+
+	"burlo/v2/pkg/authz"
+	"burlo/v2/pkg/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Server hosts the Thermostat and/or DX2W services on a single grpc.Server.
+// It's a service.Runnable: construct it, Register whichever services
+// apply, then hand it to service.Start alongside the HTTP handlers.
+type Server struct {
+	addr string
+	tls  *tls.Config
+	srv  *grpc.Server
+	log  *logger.Logger
+}
+
+// MethodScopes maps a gRPC full method name (e.g.
+// "/rpc.Thermostat/SetSetpoint") to the authz scope required to call it.
+// A method with no entry is allowed without a token.
+type MethodScopes map[string]string
+
+// NewServer builds a Server listening on addr. tlsConfig may be nil to
+// serve plaintext (e.g. behind a trusted reverse proxy); issuer may be nil
+// to skip auth entirely (e.g. for an agent-to-agent link already secured
+// by tlsConfig's mTLS). scopes maps full method names to the scope
+// required to call them; methods without an entry require no token.
+func NewServer(addr string, tlsConfig *tls.Config, issuer *authz.Issuer, scopes MethodScopes) *Server {
+	log := logger.New("RPC")
+
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	if issuer != nil {
+		opts = append(opts,
+			grpc.UnaryInterceptor(authUnaryInterceptor(issuer, scopes)),
+			grpc.StreamInterceptor(authStreamInterceptor(issuer, scopes)),
+		)
+	}
+
+	return &Server{
+		addr: addr,
+		tls:  tlsConfig,
+		srv:  grpc.NewServer(opts...),
+		log:  log,
+	}
+}
+
+// RegisterThermostat registers srv as the Thermostat service.
+func (s *Server) RegisterThermostat(srv ThermostatServer) {
+	RegisterThermostatServer(s.srv, srv)
+}
+
+// RegisterDX2W registers srv as the DX2W service.
+func (s *Server) RegisterDX2W(srv DX2WServer) {
+	RegisterDX2WServer(s.srv, srv)
+}
+
+func (s *Server) Run(ctx context.Context) {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		s.log.Error("listen on %s failed: %v", s.addr, err)
+		return
+	}
+
+	s.log.Info("serving gRPC control plane on %s (tls=%v)", s.addr, s.tls != nil)
+	go func() {
+		<-ctx.Done()
+		s.srv.GracefulStop()
+	}()
+
+	if err := s.srv.Serve(lis); err != nil {
+		s.log.Error("grpc server stopped: %v", err)
+	}
+}
+
+// authUnaryInterceptor rejects a unary call unless it carries a bearer
+// token valid for the scope method requires, skipping the check entirely
+// for methods not listed in scopes.
+func authUnaryInterceptor(issuer *authz.Issuer, scopes MethodScopes) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := checkAuth(ctx, issuer, scopes, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authStreamInterceptor is authUnaryInterceptor's streaming-call
+// counterpart, checked once before the first message is read.
+func authStreamInterceptor(issuer *authz.Issuer, scopes MethodScopes) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkAuth(ss.Context(), issuer, scopes, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func checkAuth(ctx context.Context, issuer *authz.Issuer, scopes MethodScopes, fullMethod string) error {
+	scope, ok := scopes[fullMethod]
+	if !ok {
+		return nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing metadata")
+	}
+	auth := firstValue(md, "authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	token := strings.TrimPrefix(auth, "Bearer ")
+
+	if _, err := issuer.Verify(token, scope); err != nil {
+		return status.Error(codes.PermissionDenied, fmt.Sprintf("invalid token: %v", err))
+	}
+	return nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,37 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sysmon
+
+import "fmt"
+
+// DiskHealthChecker reports unhealthy once free space on Path drops below
+// MinFreeBytes. It satisfies rootserv.HealthChecker (Health() error)
+// without sysmon importing rootserv.
+type DiskHealthChecker struct {
+	Path         string
+	MinFreeBytes uint64
+}
+
+func (d DiskHealthChecker) Health() error {
+	_, free, _, err := DiskUsage(d.Path)
+	if err != nil {
+		return fmt.Errorf("disk usage for %s: %w", d.Path, err)
+	}
+	if free < d.MinFreeBytes {
+		return fmt.Errorf("disk free on %s is %d bytes, below the %d byte threshold", d.Path, free, d.MinFreeBytes)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sysmon
+
+import (
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+var (
+	cpuSystemPercentDesc  = prometheus.NewDesc("burlo_cpu_system_percent", "System-wide CPU utilization percent.", nil, nil)
+	cpuProcessPercentDesc = prometheus.NewDesc("burlo_cpu_process_percent", "Burlo process CPU utilization percent.", nil, nil)
+	memSystemBytesDesc    = prometheus.NewDesc("burlo_memory_system_bytes", "System memory in bytes.", []string{"state"}, nil)
+	memProcessRSSDesc     = prometheus.NewDesc("burlo_memory_process_rss_bytes", "Resident memory (RSS) of the Burlo process.", nil, nil)
+	diskBytesDesc         = prometheus.NewDesc("burlo_disk_bytes", "Disk space in bytes.", []string{"mount", "state"}, nil)
+)
+
+// collector implements prometheus.Collector by recomputing system stats on
+// every scrape: the same CPU and memory values rendered by
+// Service.ServeHTTP, with disk usage reported for dir (the working
+// directory) rather than "/".
+type collector struct {
+	dir string
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cpuSystemPercentDesc
+	ch <- cpuProcessPercentDesc
+	ch <- memSystemBytesDesc
+	ch <- memProcessRSSDesc
+	ch <- diskBytesDesc
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	cpuPercentList, _ := cpu.Percent(0, false)
+	cpuPercent := 0.0
+	if len(cpuPercentList) > 0 {
+		cpuPercent = cpuPercentList[0]
+	}
+	ch <- prometheus.MustNewConstMetric(cpuSystemPercentDesc, prometheus.GaugeValue, cpuPercent)
+
+	if vmem, err := mem.VirtualMemory(); err == nil {
+		ch <- prometheus.MustNewConstMetric(memSystemBytesDesc, prometheus.GaugeValue, float64(vmem.Total), "total")
+		ch <- prometheus.MustNewConstMetric(memSystemBytesDesc, prometheus.GaugeValue, float64(vmem.Used), "used")
+		ch <- prometheus.MustNewConstMetric(memSystemBytesDesc, prometheus.GaugeValue, float64(vmem.Available), "free")
+	}
+
+	if total, free, used, err := DiskUsage(c.dir); err == nil {
+		ch <- prometheus.MustNewConstMetric(diskBytesDesc, prometheus.GaugeValue, float64(total), c.dir, "total")
+		ch <- prometheus.MustNewConstMetric(diskBytesDesc, prometheus.GaugeValue, float64(used), c.dir, "used")
+		ch <- prometheus.MustNewConstMetric(diskBytesDesc, prometheus.GaugeValue, float64(free), c.dir, "free")
+	}
+
+	p, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return
+	}
+	if memInfo, err := p.MemoryInfo(); err == nil {
+		ch <- prometheus.MustNewConstMetric(memProcessRSSDesc, prometheus.GaugeValue, float64(memInfo.RSS))
+	}
+	if pct, err := p.CPUPercent(); err == nil {
+		ch <- prometheus.MustNewConstMetric(cpuProcessPercentDesc, prometheus.GaugeValue, pct)
+	}
+}
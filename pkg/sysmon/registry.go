@@ -0,0 +1,32 @@
+// Copyright (C) 2025 Josh Simonot
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sysmon
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Registry is the process-wide Prometheus registry. sysmon.Service owns the
+// HTTP `/metrics` endpoint, but any subsystem (modbus, controller, rootserv,
+// ...) can register its own prometheus.Collector here so domain metrics are
+// scraped from the same endpoint without standing up a second HTTP server.
+type Registry struct {
+	*prometheus.Registry
+}
+
+// NewRegistry creates an empty Registry. Pass it to sysmon.New and to the
+// constructors of any other subsystem that publishes metrics.
+func NewRegistry() *Registry {
+	return &Registry{Registry: prometheus.NewRegistry()}
+}
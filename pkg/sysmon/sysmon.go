@@ -25,28 +25,41 @@ import (
 
 	"burlo/v2/pkg/logger"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/shirou/gopsutil/v3/process"
 )
 
 type Service struct {
-	dir string
-	log *logger.Logger
+	dir     string
+	log     *logger.Logger
+	reg     *Registry
+	metrics http.Handler
 }
 
-func New() *Service {
+// New creates the system monitor service and registers its collector on reg,
+// so its metrics are served alongside every other subsystem's at /metrics.
+func New(reg *Registry) *Service {
 	dir, err := os.Getwd()
 	if err != nil {
 		log.Fatalf("Fatal: Error getting working directory: %v\n", err)
 	}
+	reg.MustRegister(&collector{dir: dir})
 	return &Service{
-		log: logger.New("System Monitor"),
-		dir: dir,
+		log:     logger.New("System Monitor"),
+		dir:     dir,
+		reg:     reg,
+		metrics: promhttp.HandlerFor(reg.Registry, promhttp.HandlerOpts{}),
 	}
 }
 
 func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/metrics" {
+		s.metrics.ServeHTTP(w, r)
+		return
+	}
+
 	// System-wide CPU and memory
 	cpuPercentList, _ := cpu.Percent(0, false)
 	cpuPercent := 0.0
@@ -16,14 +16,33 @@
 package zwavejsws
 
 import (
+	"burlo/v2/pkg/agentrpc"
 	"burlo/v2/pkg/logger"
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+)
+
+const (
+	// pingInterval is how often a liveness ping is written to the local
+	// websocket; readTimeout is how long the read loop waits for any
+	// traffic (including the resulting pong) before giving up on the
+	// connection.
+	pingInterval = 20 * time.Second
+	readTimeout  = 45 * time.Second
+
+	minBackoff = 1 * time.Second
+	maxBackoff = 60 * time.Second
+
+	maxQueuedWrites = 32
 )
 
 // ---------- Types ----------
@@ -138,24 +157,66 @@ type UpdatedMetadata struct {
 	Metadata         Metadata `json:"metadata"`
 }
 
-// Client manages websocket communication
+// Client manages websocket communication, either directly with a local
+// zwave-js-server (the default) or, when built via NewRemoteClient,
+// through an agentrpc.AgentClient talking to a cmd/burlo-agent process
+// that itself owns the local connection. Both modes drive the same
+// OnState/OnEvent/SetValue surface so callers don't need to care which
+// one is in play.
 type Client struct {
-	url       string
-	conn      *websocket.Conn
-	mu        sync.Mutex
-	onState   func(State)
-	onEvent   func(Event)
-	retryWait time.Duration
-	log       *logger.Logger
+	url         string
+	token       string
+	conn        *websocket.Conn
+	mu          sync.Mutex
+	onState     func(State)
+	onEvent     func(Event)
+	onConnState func(bool)
+	log         *logger.Logger
+
+	// remote mode: set by NewRemoteClient. agentConn/agentStream are
+	// established by Run and torn down in Close.
+	agentAddr   string
+	agentConn   *grpc.ClientConn
+	agentStream agentrpc.AgentService_StreamClient
+
+	// writeQueue holds SetValue calls made while disconnected, replayed in
+	// order once Run reconnects. Bounded, drop-oldest.
+	writeMu    sync.Mutex
+	writeQueue []setValueCall
+}
+
+type setValueCall struct {
+	nodeID       int
+	commandClass int
+	property     string
+	value        interface{}
 }
 
 // ---------- Public API ----------
 
-func NewClient(url string) *Client {
+// NewClient builds a Client that dials the zwave-js-server at url
+// directly. token is optional; if non-empty, it's sent as
+// "Authorization: Bearer <token>" on every dial, exercising
+// zwave-js-server's own auth when configured.
+func NewClient(url, token string) *Client {
 	return &Client{
-		url:       url,
-		retryWait: 5 * time.Second,
-		log:       logger.New("ZWaveJS   "),
+		url:   url,
+		token: token,
+		log:   logger.New("ZWaveJS   ").With("addr", url),
+	}
+}
+
+// NewRemoteClient builds a Client that reaches zwave-js-server indirectly,
+// through a cmd/burlo-agent process hosting the agentrpc Agent service at
+// agentAddr. Unlike the local mode, the remote agent owns the zwave-js
+// node topology walk itself, so OnState's callback is never invoked here;
+// callers relying on a remote client must already know the node id(s)
+// they care about (e.g. from config) rather than discovering them from
+// Result.State.
+func NewRemoteClient(agentAddr string) *Client {
+	return &Client{
+		agentAddr: agentAddr,
+		log:       logger.New("ZWaveJS   ").With("agentAddr", agentAddr),
 	}
 }
 
@@ -169,7 +230,22 @@ func (c *Client) OnEvent(fn func(Event)) {
 	c.onEvent = fn
 }
 
-// SendCommand sends a generic command to zwave-js
+// OnConnState sets the callback invoked with true right after Run
+// establishes a connection and with false right after it's lost, so
+// callers (e.g. the /thermostat web UI) can surface "zwave offline".
+func (c *Client) OnConnState(fn func(bool)) {
+	c.onConnState = fn
+}
+
+func (c *Client) setConnState(connected bool) {
+	if c.onConnState != nil {
+		c.onConnState(connected)
+	}
+}
+
+// SendCommand sends a generic command to zwave-js. Only meaningful in
+// local mode; remote mode only ever sends SetValue commands, which go
+// straight to the agentrpc stream.
 func (c *Client) SendCommand(msg interface{}) error {
 	if c == nil {
 		return fmt.Errorf("client is nil")
@@ -182,110 +258,362 @@ func (c *Client) SendCommand(msg interface{}) error {
 	return c.conn.WriteJSON(msg)
 }
 
-// SetValue sets a value on a node
+// SetValue sets a value on a node, either by sending zwave-js's
+// node.set_value command directly (local mode) or by pushing an
+// agentrpc.Command down the remote stream (remote mode). If the
+// connection is currently down, the call is queued and replayed in order
+// once Run reconnects, rather than failing outright.
 func (c *Client) SetValue(nodeID int, commandClass int, property string, value interface{}) error {
+	call := setValueCall{nodeID: nodeID, commandClass: commandClass, property: property, value: value}
+
+	if c.agentAddr != "" {
+		c.mu.Lock()
+		stream := c.agentStream
+		c.mu.Unlock()
+		if stream == nil {
+			c.queueWrite(call)
+			return nil
+		}
+		return stream.Send(&agentrpc.Command{
+			NodeID:       strconv.Itoa(call.nodeID),
+			CommandClass: call.commandClass,
+			Property:     call.property,
+			Value:        call.value,
+		})
+	}
+
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		c.queueWrite(call)
+		return nil
+	}
+	return c.writeSetValue(call)
+}
+
+// writeSetValue sends a single queued/direct SetValue call over the local
+// websocket.
+func (c *Client) writeSetValue(call setValueCall) error {
 	cmd := map[string]interface{}{
 		"command": "node.set_value",
-		"nodeId":  nodeID,
+		"nodeId":  call.nodeID,
 		"args": map[string]interface{}{
-			"commandClass": commandClass,
-			"property":     property,
-			"value":        value,
+			"commandClass": call.commandClass,
+			"property":     call.property,
+			"value":        call.value,
 		},
 	}
 	return c.SendCommand(cmd)
 }
 
-// Connect starts the connection loop
-func (c *Client) Connect(ctx context.Context) error {
+// queueWrite appends call to the bounded write queue, dropping the oldest
+// entry if it's full.
+func (c *Client) queueWrite(call setValueCall) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if len(c.writeQueue) >= maxQueuedWrites {
+		c.writeQueue = c.writeQueue[1:]
+	}
+	c.writeQueue = append(c.writeQueue, call)
+}
+
+// drainWriteQueue replays any SetValue calls queued while disconnected, in
+// order, right after Run re-establishes a connection.
+func (c *Client) drainWriteQueue() {
+	c.writeMu.Lock()
+	queue := c.writeQueue
+	c.writeQueue = nil
+	c.writeMu.Unlock()
+
+	for _, call := range queue {
+		var err error
+		if c.agentAddr != "" {
+			c.mu.Lock()
+			stream := c.agentStream
+			c.mu.Unlock()
+			if stream == nil {
+				continue
+			}
+			err = stream.Send(&agentrpc.Command{
+				NodeID:       strconv.Itoa(call.nodeID),
+				CommandClass: call.commandClass,
+				Property:     call.property,
+				Value:        call.value,
+			})
+		} else {
+			err = c.writeSetValue(call)
+		}
+		if err != nil {
+			c.log.Error("replay of queued SetValue failed: %v", err)
+			return
+		}
+	}
+}
+
+// Run dials zwave-js (directly in local mode, or through a cmd/burlo-agent
+// process in remote mode) and services it until ctx is canceled,
+// reconnecting with exponential backoff on any failure. It replaces the
+// old pattern of callers driving Connect/ListenNext/Close themselves,
+// which had no recovery from a dead connection.
+func (c *Client) Run(ctx context.Context) error {
+	if c.agentAddr != "" {
+		return c.runRemote(ctx)
+	}
+	return c.runLocal(ctx)
+}
+
+// Close force-closes whichever connection is currently open. Run notices
+// and reconnects; it's meant for callers that want to force a reconnect
+// or tear down outside of ctx cancellation.
+func (c *Client) Close() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-
-	// already connected
 	if c.conn != nil {
-		return nil
+		c.conn.Close()
+	}
+	if c.agentConn != nil {
+		c.agentConn.Close()
+	}
+}
+
+func (c *Client) runLocal(ctx context.Context) error {
+	backoff := minBackoff
+	for ctx.Err() == nil {
+		conn, err := c.dialLocal(ctx)
+		if err != nil {
+			c.log.Error("zwave: connect failed: %v (%v), retrying in %s", err, c.url, backoff)
+			if !sleepBackoff(ctx, backoff) {
+				return nil
+			}
+			backoff = nextBackoff(backoff)
+			continue
+		}
+		backoff = minBackoff
+
+		c.mu.Lock()
+		c.conn = conn
+		c.mu.Unlock()
+		c.log.Info("Connected")
+		c.setConnState(true)
+		c.drainWriteQueue()
+
+		c.serveLocal(ctx, conn)
+
+		c.mu.Lock()
+		if c.conn == conn {
+			c.conn = nil
+		}
+		c.mu.Unlock()
+		conn.Close()
+		c.setConnState(false)
+		c.log.Info("Closed")
 	}
+	return nil
+}
 
-	conn, _, err := websocket.DefaultDialer.Dial(c.url, nil)
-	if err != nil {
-		c.log.Error("zwave: connect failed: %v (%v), retrying in %s", err, c.url, c.retryWait)
-		return err
+// dialLocal opens the websocket and sends the initialize/start_listening
+// handshake zwave-js-server expects on every new connection.
+func (c *Client) dialLocal(ctx context.Context) (*websocket.Conn, error) {
+	var headers http.Header
+	if c.token != "" {
+		headers = http.Header{"Authorization": []string{"Bearer " + c.token}}
 	}
 
-	// When the context is cancelled, close the websocket to unblock reads
-	go func() {
-		<-ctx.Done()
-		c.Close()
-	}()
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.url, headers)
+	if err != nil {
+		return nil, err
+	}
 
-	// Initialize
-	err = conn.WriteJSON(map[string]any{
+	if err := conn.WriteJSON(map[string]any{
 		"messageId":     "initialize",
 		"command":       "initialize",
-		"schemaVersion": 1})
-
-	if err != nil {
-		c.log.Error("zwave command: initialize failed: %v", err)
-		return err
+		"schemaVersion": 1,
+	}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("initialize: %w", err)
 	}
 
-	// Start listening
-	err = conn.WriteJSON(map[string]any{
+	if err := conn.WriteJSON(map[string]any{
 		"messageId": "start_listening",
 		"command":   "start_listening",
+	}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("start_listening: %w", err)
+	}
+
+	return conn, nil
+}
+
+// serveLocal runs the read loop and liveness ping ticker for one
+// connection, returning once ctx is canceled or any read/write fails.
+func (c *Client) serveLocal(ctx context.Context, conn *websocket.Conn) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// ReadMessage below has no ctx of its own, so force-close the
+	// connection on cancellation to unblock it.
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+		return nil
 	})
 
-	if err != nil {
-		c.log.Error("zwave command: start_listening failed: %v", err)
-		return err
-	}
+	go func() {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				// WriteControl may be called concurrently with the
+				// read loop and SetValue's writes per gorilla/websocket's
+				// own concurrency guarantees.
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
 
-	c.conn = conn
-	c.log.Info("Connected")
-	return nil
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			c.log.Error("zwave-js ws ReadMessage: %v", err)
+			return
+		}
+
+		var resp Response
+		if err := json.Unmarshal(data, &resp); err != nil {
+			c.log.Error("Unmarshal of zwave-js message: %v", err)
+			continue
+		}
+
+		switch resp.Type {
+		case "result":
+			c.handleResponse(resp)
+		case "event":
+			c.handleEvent(resp)
+		default:
+			c.log.Info("unhandled zwave-js message type: %s", resp.Type)
+		}
+	}
 }
 
-// Close stops the client
-func (c *Client) Close() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if c.conn != nil {
-		tmpConn := c.conn
-		c.conn = nil
-		tmpConn.Close()
+func (c *Client) runRemote(ctx context.Context) error {
+	backoff := minBackoff
+	for ctx.Err() == nil {
+		conn, stream, err := c.dialRemote(ctx)
+		if err != nil {
+			c.log.Error("zwave: agent dial failed: %v (%v), retrying in %s", err, c.agentAddr, backoff)
+			if !sleepBackoff(ctx, backoff) {
+				return nil
+			}
+			backoff = nextBackoff(backoff)
+			continue
+		}
+		backoff = minBackoff
+
+		c.mu.Lock()
+		c.agentConn = conn
+		c.agentStream = stream
+		c.mu.Unlock()
+		c.log.Info("Connected")
+		c.setConnState(true)
+		c.drainWriteQueue()
+
+		c.serveRemote(stream)
+
+		c.mu.Lock()
+		if c.agentStream == stream {
+			c.agentConn = nil
+			c.agentStream = nil
+		}
+		c.mu.Unlock()
+		conn.Close()
+		c.setConnState(false)
 		c.log.Info("Closed")
 	}
+	return nil
 }
 
-func (c *Client) ListenNext() error {
-	_, data, err := c.conn.ReadMessage()
+func (c *Client) dialRemote(ctx context.Context) (*grpc.ClientConn, agentrpc.AgentService_StreamClient, error) {
+	conn, err := agentrpc.Dial(c.agentAddr)
 	if err != nil {
-		if c.conn == nil {
-			return nil // was closed
-		}
-		c.log.Error("zwave-js ws ReadMessage: %v", err)
-		return err
+		return nil, nil, err
 	}
-
-	var resp Response
-	if err := json.Unmarshal(data, &resp); err != nil {
-		c.log.Error("Unmarshal of zwave-js message: %v", err)
-		return err
+	stream, err := agentrpc.NewAgentClient(conn).Stream(ctx)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
 	}
+	return conn, stream, nil
+}
 
-	switch resp.Type {
-	case "result":
-		c.handleResponse(resp)
-		return nil
+// serveRemote reads Samples off the agentrpc stream and turns each into a
+// synthetic "value updated" Event, so OnEvent callbacks written against
+// the local protocol work unchanged against a remote agent. It returns
+// once ctx is canceled (Recv then fails, since ctx was passed to Stream)
+// or the stream otherwise errors.
+func (c *Client) serveRemote(stream agentrpc.AgentService_StreamClient) {
+	for {
+		sample, err := stream.Recv()
+		if err != nil {
+			c.log.Error("agent stream Recv: %v", err)
+			return
+		}
 
-	case "event":
-		c.handleEvent(resp)
-		return nil
+		if c.onEvent == nil {
+			continue
+		}
 
-	default:
-		c.log.Info("unhandled zwave-js message type: %s", resp.Type)
-		return nil
+		nodeID, err := strconv.Atoi(sample.NodeID)
+		if err != nil {
+			c.log.Error("agent sample has non-numeric node id %q: %v", sample.NodeID, err)
+			continue
+		}
+
+		args, err := json.Marshal(UpdatedValue{
+			Property:     sample.SensorID,
+			PropertyName: sample.SensorID,
+			NewValue:     sample.Value,
+		})
+		if err != nil {
+			continue
+		}
+
+		c.onEvent(Event{Type: "value updated", NodeID: nodeID, Args: args})
+	}
+}
+
+// sleepBackoff waits backoff plus jitter, returning false if ctx is
+// canceled first.
+func sleepBackoff(ctx context.Context, backoff time.Duration) bool {
+	jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(backoff + jitter):
+		return true
+	}
+}
+
+func nextBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > maxBackoff {
+		backoff = maxBackoff
 	}
+	return backoff
 }
 
 // ---------- Internal ----------